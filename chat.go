@@ -9,8 +9,10 @@ import (
 	"strconv"
 )
 
+type PersonaState uint8
+
 const (
-	PersonaStateOffline = iota
+	PersonaStateOffline PersonaState = iota
 	PersonaStateOnline
 	PersonaStateBusy
 	PersonaStateAway
@@ -19,6 +21,44 @@ const (
 	PersonaStateLookingToPlay
 )
 
+var personaStateNames = enumNames{
+	uint64(PersonaStateOffline):        "Offline",
+	uint64(PersonaStateOnline):         "Online",
+	uint64(PersonaStateBusy):           "Busy",
+	uint64(PersonaStateAway):           "Away",
+	uint64(PersonaStateSnooze):         "Snooze",
+	uint64(PersonaStateLookingToTrade): "LookingToTrade",
+	uint64(PersonaStateLookingToPlay):  "LookingToPlay",
+}
+
+func (state PersonaState) String() string {
+	return personaStateNames.format(uint64(state))
+}
+
+func (state PersonaState) MarshalText() ([]byte, error) {
+	return []byte(state.String()), nil
+}
+
+func (state *PersonaState) UnmarshalText(text []byte) error {
+	v, err := personaStateNames.parse(text, 8)
+	if err != nil {
+		return err
+	}
+
+	*state = PersonaState(v)
+	return nil
+}
+
+// UnmarshalJSON accepts both the numbers sent by Steam and names.
+func (state *PersonaState) UnmarshalJSON(data []byte) error {
+	v, ok, err := personaStateNames.unmarshalJSON(data, 8)
+	if ok {
+		*state = PersonaState(v)
+	}
+
+	return err
+}
+
 const (
 	PersonaStateFlagRichPresence   = 1 << 0
 	PersonaStateFlagInJoinableGame = 1 << 1
@@ -46,14 +86,14 @@ const (
 )
 
 type ChatMessage struct {
-	Type         string `json:"type"`
-	Text         string `json:"text"`
-	TimestampOff int64  `json:"timestamp"`
-	UTCTimestamp int64  `json:"utc_timestamp"`
-	Partner      uint32 `json:"accountid_from"`
-	StatusFlags  uint32 `json:"status_flags"`
-	PersonaState uint32 `json:"persona_state"`
-	PersonaName  string `json:"persona_name"`
+	Type         string       `json:"type"`
+	Text         string       `json:"text"`
+	TimestampOff int64        `json:"timestamp"`
+	UTCTimestamp int64        `json:"utc_timestamp"`
+	Partner      uint32       `json:"accountid_from"`
+	StatusFlags  uint32       `json:"status_flags"`
+	PersonaState PersonaState `json:"persona_state"`
+	PersonaName  string       `json:"persona_name"`
 }
 
 type ChatLogMessage struct {
@@ -76,17 +116,17 @@ type ChatResponse struct {
 }
 
 type ChatFriendResponse struct {
-	AccountID   uint32  `json:"m_unAccountID"`
-	SteamID     SteamID `json:"m_ulSteamID,string"`
-	Name        string  `json:"m_strName"`
-	State       uint8   `json:"m_ePersonaState"`
-	StateFlags  uint32  `json:"m_nPersonaStateFlags"`
-	AvatarHash  string  `json:"m_strAvatarHash"`
-	InGame      bool    `json:"m_bIngame"`
-	InGameAppID uint64  `json:"m_nInGameAppID,string"`
-	InGameName  string  `json:"m_strInGameName"`
-	LastMessage int64   `json:"m_tsLastMessage"`
-	LastView    int64   `json:"m_tsLastView"`
+	AccountID   uint32       `json:"m_unAccountID"`
+	SteamID     SteamID      `json:"m_ulSteamID,string"`
+	Name        string       `json:"m_strName"`
+	State       PersonaState `json:"m_ePersonaState"`
+	StateFlags  uint32       `json:"m_nPersonaStateFlags"`
+	AvatarHash  string       `json:"m_strAvatarHash"`
+	InGame      bool         `json:"m_bIngame"`
+	InGameAppID uint64       `json:"m_nInGameAppID,string"`
+	InGameName  string       `json:"m_strInGameName"`
+	LastMessage int64        `json:"m_tsLastMessage"`
+	LastView    int64        `json:"m_tsLastView"`
 }
 
 func (session *Session) ChatLogin(uiMode string) error {
@@ -1,36 +1,110 @@
 package steam
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"io"
-	"net/http"
-
 	"fmt"
+	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+)
+
+type ConfirmationType uint8
 
-	"github.com/PuerkitoBio/goquery"
+const (
+	ConfirmationTypeInvalid ConfirmationType = iota
+	ConfirmationTypeTest
+	ConfirmationTypeTrade
+	ConfirmationTypeMarketListing
+	ConfirmationTypeFeatureOptOut
+	ConfirmationTypePhoneNumberChange
+	ConfirmationTypeAccountRecovery
+	ConfirmationTypeAPIKeyRegistration ConfirmationType = 9
 )
 
 type Confirmation struct {
 	ID        uint64
-	Key       uint64
-	Title     string
-	Receiving string
-	Since     string
+	Key       uint64 // "nonce", needed to answer the confirmation
+	Type      ConfirmationType
+	TypeName  string
+	CreatorID uint64 // trade offer ID or market listing ID depending on Type
+	Headline  string
+	Summary   []string
+	Icon      string
+	Created   time.Time
+}
+
+// ConfirmationResult is the outcome of answering a single confirmation
+// through AnswerConfirmations, Err is nil when Steam accepted the answer.
+type ConfirmationResult struct {
+	Confirmation *Confirmation
+	Err          error
+}
+
+// ConfirmationTrade is the trade offer behind a ConfirmationTypeTrade
+// confirmation, items are seen from the confirming account's side.
+type ConfirmationTrade struct {
 	OfferID   uint64
+	Partner   uint32 // account ID, same as TradeOffer.Partner
+	SendItems []*EconItem
+	RecvItems []*EconItem
+}
+
+// ConfirmationListing is the market listing behind a
+// ConfirmationTypeMarketListing confirmation, prices are formatted
+// in the wallet currency, e.g. "$1.15".
+type ConfirmationListing struct {
+	Item       *EconItem
+	Name       string
+	BuyerPays  string
+	YouReceive string
+}
+
+// ConfirmationDetails holds whatever could be parsed from the details page,
+// only the field matching the confirmation type is set.  HTML is the raw
+// page for anything not covered.
+type ConfirmationDetails struct {
+	Trade   *ConfirmationTrade
+	Listing *ConfirmationListing
+	HTML    string
+}
+
+type ConfirmationEventType uint8
+
+const (
+	ConfirmationEventNew   ConfirmationEventType = iota // appeared since the last poll
+	ConfirmationEventGone                               // answered or expired since the last poll
+	ConfirmationEventError                              // polling failed, Err is set
+)
+
+type ConfirmationEvent struct {
+	Type         ConfirmationEventType
+	Confirmation *Confirmation
+	Err          error
 }
 
 var (
+	// economyItemExp matches items in the details HTML in the following form:
+	//	data-economy-item="classinfo/<APPID>/<CLASSID>/<INSTANCEID>"
+	// the instance ID is left out when it is 0.
+	economyItemExp     = regexp.MustCompile("data-economy-item=\"classinfo/(\\d+)/(\\d+)(?:/(\\d+))?\"")
+	confOfferIDExp     = regexp.MustCompile("id=\"tradeofferid_(\\d+)\"")
+	confPartnerExp     = regexp.MustCompile("data-miniprofile=\"(\\d+)\"")
+	confListingNameExp = regexp.MustCompile("class=\"market_listing_item_name\"[^>]*>\\s*([^<]+?)\\s*<")
+	confBuyerPaysExp   = regexp.MustCompile("Buyer pays:?\\s*(?:<[^>]+>\\s*)*([^<]+?)\\s*<")
+	confYouReceiveExp  = regexp.MustCompile("You receive:?\\s*(?:<[^>]+>\\s*)*([^<]+?)\\s*<")
+
 	ErrConfirmationsUnknownError = errors.New("unknown error occurered finding confirmations")
-	ErrCannotFindConfirmations   = errors.New("unable to find confirmations")
-	ErrCannotFindDescriptions    = errors.New("unable to find confirmation descriptions")
-	ErrConfiramtionsDescMismatch = errors.New("cannot match confirmations with their respective descriptions")
+	ErrConfirmationsNeedAuth     = errors.New("confirmations require authentication, session is invalid or identity secret is wrong")
+	ErrCannotFindConfDetails     = errors.New("unable to find confirmation details")
 )
 
-func (session *Session) execConfirmationRequest(request, key, tag string, current int64, values map[string]interface{}) (*http.Response, error) {
-	params := url.Values{
+func (session *Session) confirmationParams(key, tag string, current int64) url.Values {
+	return url.Values{
 		"p":   {session.deviceID},
 		"a":   {session.oauth.SteamID.ToString()},
 		"k":   {key},
@@ -38,7 +112,10 @@ func (session *Session) execConfirmationRequest(request, key, tag string, curren
 		"m":   {"android"},
 		"tag": {tag},
 	}
+}
 
+func (session *Session) execConfirmationRequest(request, key, tag string, current int64, values map[string]interface{}) (*http.Response, error) {
+	params := session.confirmationParams(key, tag, current)
 	if values != nil {
 		for k, v := range values {
 			switch v := v.(type) {
@@ -55,13 +132,16 @@ func (session *Session) execConfirmationRequest(request, key, tag string, curren
 	return session.client.Get("https://steamcommunity.com/mobileconf/" + request + params.Encode())
 }
 
+// GetConfirmations returns the pending confirmations, an empty slice means
+// there is nothing to confirm while ErrConfirmationsNeedAuth means Steam
+// refused to list them at all.
 func (session *Session) GetConfirmations(identitySecret string, current int64) ([]*Confirmation, error) {
 	key, err := GenerateConfirmationCode(identitySecret, "conf", current)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := session.execConfirmationRequest("conf?", key, "conf", current, nil)
+	resp, err := session.execConfirmationRequest("getlist?", key, "conf", current, nil)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -70,68 +150,174 @@ func (session *Session) GetConfirmations(identitySecret string, current int64) (
 		return nil, err
 	}
 
-	doc, err := goquery.NewDocumentFromReader(io.Reader(resp.Body))
-	if err != nil {
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %d", resp.StatusCode)
+	}
+
+	type Entry struct {
+		ID        uint64           `json:"id,string"`
+		Nonce     uint64           `json:"nonce,string"`
+		Type      ConfirmationType `json:"type"`
+		TypeName  string           `json:"type_name"`
+		CreatorID uint64           `json:"creator_id,string"`
+		Headline  string           `json:"headline"`
+		Summary   []string         `json:"summary"`
+		Icon      string           `json:"icon"`
+		Created   int64            `json:"creation_time"`
+	}
+
+	type Response struct {
+		Success  bool     `json:"success"`
+		NeedAuth bool     `json:"needauth"`
+		Message  string   `json:"message"`
+		Entries  []*Entry `json:"conf"`
+	}
+
+	var response Response
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, err
 	}
 
-	/* FIXME: broken
-	if empty := doc.Find(".mobileconf_empty"); empty != nil {
-		if done := doc.Find(".mobileconf_done"); done != nil {
-			return nil, nil
+	if !response.Success {
+		if response.NeedAuth {
+			return nil, ErrConfirmationsNeedAuth
+		}
+
+		if len(response.Message) != 0 {
+			return nil, errors.New(response.Message)
 		}
 
-		return nil, ErrConfirmationsUnknownError // FIXME
+		return nil, ErrConfirmationsUnknownError
 	}
-	*/
 
-	entries := doc.Find(".mobileconf_list_entry")
-	if entries == nil {
-		return nil, ErrCannotFindConfirmations
+	confirmations := make([]*Confirmation, len(response.Entries))
+	for i, entry := range response.Entries {
+		confirmations[i] = &Confirmation{
+			ID:        entry.ID,
+			Key:       entry.Nonce,
+			Type:      entry.Type,
+			TypeName:  entry.TypeName,
+			CreatorID: entry.CreatorID,
+			Headline:  entry.Headline,
+			Summary:   entry.Summary,
+			Icon:      entry.Icon,
+			Created:   time.Unix(entry.Created, 0),
+		}
 	}
 
-	descriptions := doc.Find(".mobileconf_list_entry_description")
-	if descriptions == nil {
-		return nil, ErrCannotFindDescriptions
+	return confirmations, nil
+}
+
+func parseEconomyItems(html string) []*EconItem {
+	m := economyItemExp.FindAllStringSubmatch(html, -1)
+	items := make([]*EconItem, 0, len(m))
+	for _, sub := range m {
+		appID, _ := strconv.ParseUint(sub[1], 10, 32)
+		classID, _ := strconv.ParseUint(sub[2], 10, 64)
+		instanceID, _ := strconv.ParseUint(sub[3], 10, 64)
+
+		items = append(items, &EconItem{
+			AppID:      uint32(appID),
+			ClassID:    classID,
+			InstanceID: instanceID,
+			Amount:     1,
+		})
 	}
 
-	if len(entries.Nodes) != len(descriptions.Nodes) {
-		return nil, ErrConfiramtionsDescMismatch
+	return items
+}
+
+func parseConfirmationTrade(html string) *ConfirmationTrade {
+	trade := &ConfirmationTrade{}
+	if m := confOfferIDExp.FindStringSubmatch(html); m != nil {
+		trade.OfferID, _ = strconv.ParseUint(m[1], 10, 64)
 	}
 
-	confirmations := []*Confirmation{}
-	for k, sel := range entries.Nodes {
-		confirmation := &Confirmation{}
-		for _, attr := range sel.Attr {
-			if attr.Key == "data-confid" {
-				confirmation.ID, _ = strconv.ParseUint(attr.Val, 10, 64)
-			} else if attr.Key == "data-key" {
-				confirmation.Key, _ = strconv.ParseUint(attr.Val, 10, 64)
-			} else if attr.Key == "data-creator" {
-				confirmation.OfferID, _ = strconv.ParseUint(attr.Val, 10, 64)
-			}
-		}
+	if m := confPartnerExp.FindStringSubmatch(html); m != nil {
+		partner, _ := strconv.ParseUint(m[1], 10, 32)
+		trade.Partner = uint32(partner)
+	}
 
-		descSel := descriptions.Nodes[k]
-		depth := 0
-		for child := descSel.FirstChild; child != nil; child = child.NextSibling {
-			for n := child.FirstChild; n != nil; n = n.NextSibling {
-				switch depth {
-				case 0:
-					confirmation.Title = n.Data
-				case 1:
-					confirmation.Receiving = n.Data
-				case 2:
-					confirmation.Since = n.Data
-				}
-				depth++
-			}
-		}
+	// The "primary" list holds our items, the "secondary" one theirs.
+	primary := html
+	secondary := ""
+	if i := strings.Index(html, "tradeoffer_items secondary"); i != -1 {
+		primary, secondary = html[:i], html[i:]
+	}
 
-		confirmations = append(confirmations, confirmation)
+	trade.SendItems = parseEconomyItems(primary)
+	trade.RecvItems = parseEconomyItems(secondary)
+	return trade
+}
+
+func parseConfirmationListing(html string) *ConfirmationListing {
+	listing := &ConfirmationListing{}
+	if items := parseEconomyItems(html); len(items) != 0 {
+		listing.Item = items[0]
 	}
 
-	return confirmations, nil
+	if m := confListingNameExp.FindStringSubmatch(html); m != nil {
+		listing.Name = m[1]
+	}
+
+	if m := confBuyerPaysExp.FindStringSubmatch(html); m != nil {
+		listing.BuyerPays = m[1]
+	}
+
+	if m := confYouReceiveExp.FindStringSubmatch(html); m != nil {
+		listing.YouReceive = m[1]
+	}
+
+	return listing
+}
+
+// GetConfirmationDetails loads what @confirmation will actually do, use it to
+// verify trade contents or listing prices before answering.  Listing prices
+// are matched against the English page, so keep the session language at
+// "english" if you rely on them.
+func (session *Session) GetConfirmationDetails(confirmation *Confirmation, identitySecret string, current int64) (*ConfirmationDetails, error) {
+	tag := "details" + strconv.FormatUint(confirmation.ID, 10)
+	key, err := GenerateConfirmationCode(identitySecret, tag, current)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := session.execConfirmationRequest("details/"+strconv.FormatUint(confirmation.ID, 10)+"?", key, tag, current, nil)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %d", resp.StatusCode)
+	}
+
+	type Response struct {
+		Success bool   `json:"success"`
+		HTML    string `json:"html"`
+	}
+
+	var response Response
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	if !response.Success || len(response.HTML) == 0 {
+		return nil, ErrCannotFindConfDetails
+	}
+
+	details := &ConfirmationDetails{HTML: response.HTML}
+	switch confirmation.Type {
+	case ConfirmationTypeTrade:
+		details.Trade = parseConfirmationTrade(response.HTML)
+	case ConfirmationTypeMarketListing:
+		details.Listing = parseConfirmationListing(response.HTML)
+	}
+
+	return details, nil
 }
 
 func (session *Session) AnswerConfirmation(confirmation *Confirmation, identitySecret, answer string, current int64) error {
@@ -172,6 +358,153 @@ func (session *Session) AnswerConfirmation(confirmation *Confirmation, identityS
 	return nil
 }
 
+// AnswerConfirmations answers all @confirmations with a single request.
+// The returned error is only set when the request itself failed, Steam's
+// verdict is reported through the results, one per confirmation and in the
+// same order.  Steam does not tell which confirmation of a batch was at fault,
+// so a rejected batch marks every result with the same error.
+func (session *Session) AnswerConfirmations(confirmations []*Confirmation, identitySecret, answer string, current int64) ([]*ConfirmationResult, error) {
+	if len(confirmations) == 0 {
+		return nil, nil
+	}
+
+	key, err := GenerateConfirmationCode(identitySecret, answer, current)
+	if err != nil {
+		return nil, err
+	}
+
+	params := session.confirmationParams(key, answer, current)
+	params.Set("op", answer)
+	for _, confirmation := range confirmations {
+		params.Add("cid[]", strconv.FormatUint(confirmation.ID, 10))
+		params.Add("ck[]", strconv.FormatUint(confirmation.Key, 10))
+	}
+
+	req, err := http.NewRequest(
+		http.MethodPost,
+		"https://steamcommunity.com/mobileconf/multiajaxop",
+		strings.NewReader(params.Encode()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("X-Requested-With", httpXRequestedWithValue)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := session.client.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %d", resp.StatusCode)
+	}
+
+	type Response struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	var answerErr error
+	if !response.Success {
+		if len(response.Message) != 0 {
+			answerErr = errors.New(response.Message)
+		} else {
+			answerErr = ErrConfirmationsUnknownError
+		}
+	}
+
+	results := make([]*ConfirmationResult, len(confirmations))
+	for i, confirmation := range confirmations {
+		results[i] = &ConfirmationResult{
+			Confirmation: confirmation,
+			Err:          answerErr,
+		}
+	}
+
+	return results, nil
+}
+
 func (confirmation *Confirmation) Answer(session *Session, key, answer string, current int64) error {
 	return session.AnswerConfirmation(confirmation, key, answer, current)
 }
+
+// WatchConfirmations polls confirmations every @interval and reports the ones
+// that appeared or disappeared since the previous poll, each confirmation ID
+// is reported as new only once while it stays pending.  The channel is closed
+// once @ctx is done.  Codes are generated using the session time offset, see
+// SetTimeOffset.  An @interval that is not positive defaults to 30 seconds.
+func (session *Session) WatchConfirmations(ctx context.Context, identitySecret string, interval time.Duration) <-chan *ConfirmationEvent {
+	events := make(chan *ConfirmationEvent)
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		send := func(event *ConfirmationEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		seen := make(map[uint64]*Confirmation)
+		for {
+			confirmations, err := session.GetConfirmations(identitySecret, time.Now().Add(session.timeOffset).Unix())
+			if err != nil {
+				if !send(&ConfirmationEvent{Type: ConfirmationEventError, Err: err}) {
+					return
+				}
+			} else {
+				current := make(map[uint64]*Confirmation, len(confirmations))
+				for _, confirmation := range confirmations {
+					current[confirmation.ID] = confirmation
+					if _, ok := seen[confirmation.ID]; ok {
+						continue
+					}
+
+					if !send(&ConfirmationEvent{Type: ConfirmationEventNew, Confirmation: confirmation}) {
+						return
+					}
+				}
+
+				for id, confirmation := range seen {
+					if _, ok := current[id]; ok {
+						continue
+					}
+
+					if !send(&ConfirmationEvent{Type: ConfirmationEventGone, Confirmation: confirmation}) {
+						return
+					}
+				}
+
+				seen = current
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,443 @@
+package steam
+
+import (
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type ConfirmationDecision uint8
+
+const (
+	ConfirmationDecisionNone   ConfirmationDecision = iota // no opinion, ask the next rule
+	ConfirmationDecisionLeave                              // keep it pending for a human
+	ConfirmationDecisionAllow                              // approve it
+	ConfirmationDecisionCancel                             // deny it
+)
+
+func (decision ConfirmationDecision) String() string {
+	switch decision {
+	case ConfirmationDecisionLeave:
+		return "leave"
+	case ConfirmationDecisionAllow:
+		return "allow"
+	case ConfirmationDecisionCancel:
+		return "cancel"
+	}
+
+	return "none"
+}
+
+// ConfirmationRule decides what to do with a confirmation, @details is only
+// loaded for trade and market listing confirmations and may be nil.
+// The returned reason ends up in the log.
+type ConfirmationRule func(confirmation *Confirmation, details *ConfirmationDetails) (ConfirmationDecision, string)
+
+// AllowConfirmationTypes approves every confirmation of the given types.
+func AllowConfirmationTypes(types ...ConfirmationType) ConfirmationRule {
+	return func(confirmation *Confirmation, details *ConfirmationDetails) (ConfirmationDecision, string) {
+		for _, t := range types {
+			if confirmation.Type == t {
+				return ConfirmationDecisionAllow, "allowed type " + confirmation.TypeName
+			}
+		}
+
+		return ConfirmationDecisionNone, ""
+	}
+}
+
+// CancelConfirmationTypes denies every confirmation of the given types,
+// e.g. ConfirmationTypeAPIKeyRegistration which the bot never does itself.
+func CancelConfirmationTypes(types ...ConfirmationType) ConfirmationRule {
+	return func(confirmation *Confirmation, details *ConfirmationDetails) (ConfirmationDecision, string) {
+		for _, t := range types {
+			if confirmation.Type == t {
+				return ConfirmationDecisionCancel, "denied type " + confirmation.TypeName
+			}
+		}
+
+		return ConfirmationDecisionNone, ""
+	}
+}
+
+// Confirmer polls confirmations and answers them.  Trade offers registered
+// through ExpectTradeOffer and listings through ExpectListing are approved when
+// their contents match, then Rules are asked in order, anything left undecided
+// stays pending.  Every decision is written to Logger (the standard logger if nil).
+// With a Store set, expectations and answered confirmations survive restarts.
+type Confirmer struct {
+	Interval time.Duration
+	Rules    []ConfirmationRule
+	Logger   *log.Logger
+
+	session        *Session
+	identitySecret string
+
+	mutex    sync.Mutex
+	offers   map[uint64]*TradeOffer
+	listings []*ExpectedListing
+	left     map[uint64]bool
+	handled  map[uint64]ConfirmationDecision
+	store    Store
+}
+
+// defaultConfirmerInterval is used when Interval is not positive.
+const defaultConfirmerInterval = 30 * time.Second
+
+func NewConfirmer(session *Session, identitySecret string) *Confirmer {
+	return &Confirmer{
+		Interval:       defaultConfirmerInterval,
+		session:        session,
+		identitySecret: identitySecret,
+		offers:         make(map[uint64]*TradeOffer),
+		left:           make(map[uint64]bool),
+		handled:        make(map[uint64]ConfirmationDecision),
+	}
+}
+
+// SetStore loads the state saved in @store, if any, and keeps saving it there
+// whenever it changes.
+func (confirmer *Confirmer) SetStore(store Store) error {
+	state, err := store.LoadConfirmerState()
+	if err != nil && err != ErrNotStored {
+		return err
+	}
+
+	confirmer.mutex.Lock()
+	defer confirmer.mutex.Unlock()
+
+	if state != nil {
+		for id, offer := range state.Offers {
+			confirmer.offers[id] = offer
+		}
+		confirmer.listings = append(confirmer.listings, state.Listings...)
+		for id, decision := range state.Handled {
+			confirmer.handled[id] = decision
+		}
+	}
+
+	confirmer.store = store
+	return nil
+}
+
+// save writes the current state to the store, the mutex must not be held.
+func (confirmer *Confirmer) save() error {
+	confirmer.mutex.Lock()
+	if confirmer.store == nil {
+		confirmer.mutex.Unlock()
+		return nil
+	}
+
+	state := &ConfirmerState{
+		Offers:   make(map[uint64]*TradeOffer, len(confirmer.offers)),
+		Listings: append([]*ExpectedListing(nil), confirmer.listings...),
+		Handled:  make(map[uint64]ConfirmationDecision, len(confirmer.handled)),
+	}
+	for id, offer := range confirmer.offers {
+		state.Offers[id] = offer
+	}
+	for id, decision := range confirmer.handled {
+		state.Handled[id] = decision
+	}
+	store := confirmer.store
+	confirmer.mutex.Unlock()
+
+	return store.SaveConfirmerState(state)
+}
+
+// ExpectTradeOffer registers an offer created with SendTradeOffer, its
+// confirmation is approved if the items match and denied otherwise.  Items
+// need class and instance IDs to be compared, offers without them are left
+// pending.
+func (confirmer *Confirmer) ExpectTradeOffer(offer *TradeOffer) {
+	confirmer.mutex.Lock()
+	confirmer.offers[offer.ID] = offer
+	confirmer.mutex.Unlock()
+
+	if err := confirmer.save(); err != nil {
+		confirmer.logf("confirmer: unable to save state: %v", err)
+	}
+}
+
+// ExpectedListing is an item put up for sale, Price is what we receive in
+// the wallet currency's smallest unit, the @price passed to SellItem.
+type ExpectedListing struct {
+	Item  *InventoryItem `json:"item"`
+	Price uint64         `json:"price"`
+}
+
+// ExpectListing registers an item put up with SellItem for @price, Steam does
+// not tell the listing ID back so the confirmation is matched by item class
+// and the price we receive instead.
+func (confirmer *Confirmer) ExpectListing(item *InventoryItem, price uint64) {
+	confirmer.mutex.Lock()
+	confirmer.listings = append(confirmer.listings, &ExpectedListing{Item: item, Price: price})
+	confirmer.mutex.Unlock()
+
+	if err := confirmer.save(); err != nil {
+		confirmer.logf("confirmer: unable to save state: %v", err)
+	}
+}
+
+func (confirmer *Confirmer) logf(format string, v ...interface{}) {
+	if confirmer.Logger != nil {
+		confirmer.Logger.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
+// comparableEconItems tells whether @items can be checked against a
+// confirmation page, which shows class and instance IDs but no amounts.
+// Items built from an inventory (see TradeOfferBuilder) carry the class,
+// offers made from bare asset IDs do not, nor can stacks be verified.
+func comparableEconItems(items []*EconItem) bool {
+	for _, item := range items {
+		if item.ClassID == 0 || item.Amount > 1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sameEconItems(expected, actual []*EconItem) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	used := make([]bool, len(actual))
+	for _, want := range expected {
+		found := false
+		for i, have := range actual {
+			if used[i] || want.AppID != have.AppID || want.ClassID != have.ClassID || want.InstanceID != have.InstanceID {
+				continue
+			}
+
+			used[i] = true
+			found = true
+			break
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expected checks @confirmation against the registered offers and listings,
+// the matched expectation is dropped once the confirmation has been answered.
+func (confirmer *Confirmer) expected(confirmation *Confirmation, details *ConfirmationDetails) (ConfirmationDecision, string, func()) {
+	confirmer.mutex.Lock()
+	defer confirmer.mutex.Unlock()
+
+	switch confirmation.Type {
+	case ConfirmationTypeTrade:
+		offer, ok := confirmer.offers[confirmation.CreatorID]
+		if !ok {
+			break
+		}
+
+		forget := func() {
+			confirmer.mutex.Lock()
+			delete(confirmer.offers, offer.ID)
+			confirmer.mutex.Unlock()
+		}
+
+		if details == nil || details.Trade == nil {
+			return ConfirmationDecisionLeave, "expected trade offer but details are unavailable", nil
+		}
+
+		if !comparableEconItems(offer.SendItems) || !comparableEconItems(offer.RecvItems) {
+			return ConfirmationDecisionLeave, "expected trade offer items lack class IDs or are stacked, cannot verify", nil
+		}
+
+		if !sameEconItems(offer.SendItems, details.Trade.SendItems) || !sameEconItems(offer.RecvItems, details.Trade.RecvItems) {
+			return ConfirmationDecisionCancel, "trade offer contents differ from the offer sent", forget
+		}
+
+		return ConfirmationDecisionAllow, "matches sent trade offer", forget
+	case ConfirmationTypeMarketListing:
+		if details == nil || details.Listing == nil || details.Listing.Item == nil {
+			break
+		}
+
+		item := details.Listing.Item
+		receive, err := parseMarketPrice(details.Listing.YouReceive, 0)
+		if err != nil {
+			return ConfirmationDecisionLeave, "unable to parse listing price " + strconv.Quote(details.Listing.YouReceive), nil
+		}
+
+		priceMismatch := false
+		for _, listing := range confirmer.listings {
+			if listing.Item.AppID != item.AppID || listing.Item.ClassID != item.ClassID || listing.Item.InstanceID != item.InstanceID {
+				continue
+			}
+
+			if uint64(math.Round(receive*100)) != listing.Price {
+				priceMismatch = true
+				continue
+			}
+
+			forget := func() {
+				confirmer.mutex.Lock()
+				for j, l := range confirmer.listings {
+					if l == listing {
+						confirmer.listings = append(confirmer.listings[:j], confirmer.listings[j+1:]...)
+						break
+					}
+				}
+				confirmer.mutex.Unlock()
+			}
+
+			return ConfirmationDecisionAllow, "matches item put up for sale", forget
+		}
+
+		if priceMismatch {
+			return ConfirmationDecisionLeave, "listing price " + details.Listing.YouReceive + " differs from the expected one", nil
+		}
+	}
+
+	return ConfirmationDecisionNone, "", nil
+}
+
+func (confirmer *Confirmer) decide(confirmation *Confirmation, details *ConfirmationDetails) (ConfirmationDecision, string, func()) {
+	if decision, reason, forget := confirmer.expected(confirmation, details); decision != ConfirmationDecisionNone {
+		return decision, reason, forget
+	}
+
+	for _, rule := range confirmer.Rules {
+		if decision, reason := rule(confirmation, details); decision != ConfirmationDecisionNone {
+			return decision, reason, nil
+		}
+	}
+
+	return ConfirmationDecisionLeave, "no rule matched", nil
+}
+
+func (confirmer *Confirmer) answer(confirmations []*Confirmation, decision ConfirmationDecision, forget map[uint64]func()) {
+	if len(confirmations) == 0 {
+		return
+	}
+
+	answer := decision.String()
+	results, err := confirmer.session.AnswerConfirmations(
+		confirmations,
+		confirmer.identitySecret,
+		answer,
+		time.Now().Add(confirmer.session.timeOffset).Unix(),
+	)
+	if err != nil {
+		confirmer.logf("confirmer: unable to %s %d confirmation(s): %v", answer, len(confirmations), err)
+		return
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			confirmer.logf("confirmer: unable to %s confirmation %d: %v", answer, result.Confirmation.ID, result.Err)
+			continue
+		}
+
+		if f, ok := forget[result.Confirmation.ID]; ok {
+			f()
+		}
+
+		confirmer.mutex.Lock()
+		confirmer.handled[result.Confirmation.ID] = decision
+		confirmer.mutex.Unlock()
+	}
+}
+
+// Poll fetches pending confirmations once and answers them.
+func (confirmer *Confirmer) Poll() error {
+	current := time.Now().Add(confirmer.session.timeOffset).Unix()
+	confirmations, err := confirmer.session.GetConfirmations(confirmer.identitySecret, current)
+	if err != nil {
+		return err
+	}
+
+	var allow, cancel []*Confirmation
+	forget := make(map[uint64]func())
+	pending := make(map[uint64]bool)
+	handled := make(map[uint64]ConfirmationDecision)
+
+	for _, confirmation := range confirmations {
+		// Steam may keep listing a confirmation for a moment after it was
+		// answered, also covers answers given right before a restart.
+		confirmer.mutex.Lock()
+		answered, ok := confirmer.handled[confirmation.ID]
+		confirmer.mutex.Unlock()
+		if ok {
+			handled[confirmation.ID] = answered
+			continue
+		}
+
+		var details *ConfirmationDetails
+		if confirmation.Type == ConfirmationTypeTrade || confirmation.Type == ConfirmationTypeMarketListing {
+			details, err = confirmer.session.GetConfirmationDetails(confirmation, confirmer.identitySecret, current)
+			if err != nil {
+				confirmer.logf("confirmer: unable to load details of confirmation %d: %v", confirmation.ID, err)
+			}
+		}
+
+		decision, reason, f := confirmer.decide(confirmation, details)
+		if f != nil {
+			forget[confirmation.ID] = f
+		}
+
+		switch decision {
+		case ConfirmationDecisionAllow:
+			allow = append(allow, confirmation)
+		case ConfirmationDecisionCancel:
+			cancel = append(cancel, confirmation)
+		default:
+			pending[confirmation.ID] = true
+			if confirmer.left[confirmation.ID] {
+				// Already logged on a previous poll.
+				continue
+			}
+		}
+
+		confirmer.logf("confirmer: %s confirmation %d (%s, creator %d): %s",
+			decision, confirmation.ID, confirmation.TypeName, confirmation.CreatorID, reason)
+	}
+	confirmer.left = pending
+
+	// Answered confirmations no longer listed can be forgotten.
+	confirmer.mutex.Lock()
+	confirmer.handled = handled
+	confirmer.mutex.Unlock()
+
+	confirmer.answer(allow, ConfirmationDecisionAllow, forget)
+	confirmer.answer(cancel, ConfirmationDecisionCancel, forget)
+	return confirmer.save()
+}
+
+// Run polls every Interval until @ctx is done, polling errors are logged
+// and do not stop the loop.
+func (confirmer *Confirmer) Run(ctx context.Context) error {
+	interval := confirmer.Interval
+	if interval <= 0 {
+		interval = defaultConfirmerInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := confirmer.Poll(); err != nil {
+			confirmer.logf("confirmer: poll failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
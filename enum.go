@@ -0,0 +1,59 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// enumNames maps the values of a named constant type to their names, it backs
+// the String, MarshalText and UnmarshalText methods of those types.  Values
+// without a name are formatted as plain numbers.
+type enumNames map[uint64]string
+
+func (names enumNames) format(v uint64) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+
+	return strconv.FormatUint(v, 10)
+}
+
+// parse accepts either a name (case insensitive) or a number.
+func (names enumNames) parse(text []byte, bitSize int) (uint64, error) {
+	s := string(text)
+	for v, name := range names {
+		if strings.EqualFold(name, s) {
+			return v, nil
+		}
+	}
+
+	v, err := strconv.ParseUint(s, 10, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("unknown value %q", s)
+	}
+
+	return v, nil
+}
+
+// unmarshalJSON accepts the numbers Steam sends as well as the names
+// MarshalText produces, ok is false for null.
+func (names enumNames) unmarshalJSON(data []byte, bitSize int) (v uint64, ok bool, err error) {
+	if string(data) == "null" {
+		return 0, false, nil
+	}
+
+	if len(data) != 0 && data[0] == '"' {
+		var s string
+		if err = json.Unmarshal(data, &s); err != nil {
+			return 0, false, err
+		}
+
+		v, err = names.parse([]byte(s), bitSize)
+		return v, err == nil, err
+	}
+
+	v, err = strconv.ParseUint(string(data), 10, bitSize)
+	return v, err == nil, err
+}
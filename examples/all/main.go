@@ -84,7 +84,7 @@ func main() {
 		log.Printf("%s -> %.2f (%s of same price)\n", v.Date, v.Price, v.Count)
 	}
 
-	overview, err := session.GetMarketItemPriceOverview(730, "DE", "3", "P90 | Asiimov (Factory New)")
+	overview, err := session.GetMarketItemPriceOverview(730, "DE", steam.CurrencyEUR, "P90 | Asiimov (Factory New)")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -113,13 +113,13 @@ func main() {
 		log.Printf("Offer partner SteamID 64: %d", uint64(sid))
 	}
 
-	items, err := session.GetTradeReceivedItems(receiptID)
+	trade, err := session.GetTradeStatus(receiptID)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	for _, item := range items {
-		log.Printf("New asset id: %d", item.AssetID)
+	for _, asset := range trade.AssetsReceived {
+		log.Printf("New asset id: %d", asset.NewAssetID)
 	}
 
 	identity := os.Getenv("steamIdentitySecret")
@@ -131,10 +131,11 @@ func main() {
 	for i := range confirmations {
 		c := confirmations[i]
 		log.Printf("Confirmation ID: %d, Key: %d\n", c.ID, c.Key)
-		log.Printf("-> Title %s\n", c.Title)
-		log.Printf("-> Receiving %s\n", c.Receiving)
-		log.Printf("-> Since %s\n", c.Since)
-		log.Printf("-> OfferID %d\n", c.OfferID)
+		log.Printf("-> Type %d (%s)\n", c.Type, c.TypeName)
+		log.Printf("-> Headline %s\n", c.Headline)
+		log.Printf("-> Summary %v\n", c.Summary)
+		log.Printf("-> Created %s\n", c.Created)
+		log.Printf("-> CreatorID %d\n", c.CreatorID)
 
 		err = session.AnswerConfirmation(c, key, "allow", time.Now().Add(timeDiff).Unix())
 		if err != nil {
@@ -16,12 +16,12 @@ func processOffer(session *steam.Session, offer *steam.TradeOffer) {
 	log.Printf("Offer id: %d, Receipt ID: %d", offer.ID, offer.ReceiptID)
 	log.Printf("Offer partner SteamID 64: %d", uint64(sid))
 	if offer.State == steam.TradeStateAccepted {
-		items, err := session.GetTradeReceivedItems(offer.ReceiptID)
+		trade, err := session.GetTradeStatus(offer.ReceiptID)
 		if err != nil {
 			log.Printf("error getting items: %v", err)
 		} else {
-			for _, item := range items {
-				log.Printf("Item: %#v", item)
+			for _, asset := range trade.AssetsReceived {
+				log.Printf("Item: %d (was %d)", asset.NewAssetID, asset.AssetID)
 			}
 		}
 	}
@@ -52,6 +52,7 @@ type Session struct {
 	umqID       string
 	chatMessage int
 	language    string
+	timeOffset  time.Duration
 }
 
 const (
@@ -238,6 +239,8 @@ func (session *Session) Login(accountName, password, sharedSecret string, timeOf
 		return err
 	}
 
+	session.timeOffset = timeOffset
+
 	var twoFactorCode string
 	if len(sharedSecret) != 0 {
 		if twoFactorCode, err = GenerateTwoFactorCode(sharedSecret, time.Now().Add(timeOffset).Unix()); err != nil {
@@ -256,6 +259,12 @@ func (session *Session) SetLanguage(lang string) {
 	session.language = lang
 }
 
+// SetTimeOffset sets the difference to Steam's clock used by helpers that
+// generate codes on their own, Login sets it already.
+func (session *Session) SetTimeOffset(timeOffset time.Duration) {
+	session.timeOffset = timeOffset
+}
+
 func NewSessionWithAPIKey(apiKey string) *Session {
 	return &Session{
 		client:   &http.Client{},
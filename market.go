@@ -10,50 +10,129 @@ import (
 	"strings"
 )
 
+type Currency uint32
+
 const (
-	CurrencyUSD = "1"
-	CurrencyGBP = "2"
-	CurrencyEUR = "3"
-	CurrencyCHF = "4"
-	CurrencyRUB = "5"
-	CurrencyPLN = "6"
-	CurrencyBRL = "7"
-	CurrencyJPY = "8"
-	CurrencyNOK = "9"
-	CurrencyIDR = "10"
-	CurrencyMYR = "11"
-	CurrencyPHP = "12"
-	CurrencySGD = "13"
-	CurrencyTHB = "14"
-	CurrencyVND = "15"
-	CurrencyKRW = "16"
-	CurrencyTRY = "17"
-	CurrencyUAH = "18"
-	CurrencyMXN = "19"
-	CurrencyCAD = "20"
-	CurrencyAUD = "21"
-	CurrencyNZD = "22"
-	CurrencyCNY = "23"
-	CurrencyINR = "24"
-	CurrencyCLP = "25"
-	CurrencyPEN = "26"
-	CurrencyCOP = "27"
-	CurrencyZAR = "28"
-	CurrencyHKD = "29"
-	CurrencyTWD = "30"
-	CurrencySAR = "31"
-	CurrencyAED = "32"
-	CurrencyARS = "34"
-	CurrencyILS = "35"
-	CurrencyBYN = "36"
-	CurrencyKZT = "37"
-	CurrencyKWD = "38"
-	CurrencyQAR = "39"
-	CurrencyCRC = "40"
-	CurrencyUYU = "41"
-	CurrencyRMB = "9000"
+	CurrencyUSD Currency = 1
+	CurrencyGBP Currency = 2
+	CurrencyEUR Currency = 3
+	CurrencyCHF Currency = 4
+	CurrencyRUB Currency = 5
+	CurrencyPLN Currency = 6
+	CurrencyBRL Currency = 7
+	CurrencyJPY Currency = 8
+	CurrencyNOK Currency = 9
+	CurrencyIDR Currency = 10
+	CurrencyMYR Currency = 11
+	CurrencyPHP Currency = 12
+	CurrencySGD Currency = 13
+	CurrencyTHB Currency = 14
+	CurrencyVND Currency = 15
+	CurrencyKRW Currency = 16
+	CurrencyTRY Currency = 17
+	CurrencyUAH Currency = 18
+	CurrencyMXN Currency = 19
+	CurrencyCAD Currency = 20
+	CurrencyAUD Currency = 21
+	CurrencyNZD Currency = 22
+	CurrencyCNY Currency = 23
+	CurrencyINR Currency = 24
+	CurrencyCLP Currency = 25
+	CurrencyPEN Currency = 26
+	CurrencyCOP Currency = 27
+	CurrencyZAR Currency = 28
+	CurrencyHKD Currency = 29
+	CurrencyTWD Currency = 30
+	CurrencySAR Currency = 31
+	CurrencyAED Currency = 32
+	CurrencyARS Currency = 34
+	CurrencyILS Currency = 35
+	CurrencyBYN Currency = 36
+	CurrencyKZT Currency = 37
+	CurrencyKWD Currency = 38
+	CurrencyQAR Currency = 39
+	CurrencyCRC Currency = 40
+	CurrencyUYU Currency = 41
+	CurrencyRMB Currency = 9000
 )
 
+var currencyNames = enumNames{
+	uint64(CurrencyUSD): "USD",
+	uint64(CurrencyGBP): "GBP",
+	uint64(CurrencyEUR): "EUR",
+	uint64(CurrencyCHF): "CHF",
+	uint64(CurrencyRUB): "RUB",
+	uint64(CurrencyPLN): "PLN",
+	uint64(CurrencyBRL): "BRL",
+	uint64(CurrencyJPY): "JPY",
+	uint64(CurrencyNOK): "NOK",
+	uint64(CurrencyIDR): "IDR",
+	uint64(CurrencyMYR): "MYR",
+	uint64(CurrencyPHP): "PHP",
+	uint64(CurrencySGD): "SGD",
+	uint64(CurrencyTHB): "THB",
+	uint64(CurrencyVND): "VND",
+	uint64(CurrencyKRW): "KRW",
+	uint64(CurrencyTRY): "TRY",
+	uint64(CurrencyUAH): "UAH",
+	uint64(CurrencyMXN): "MXN",
+	uint64(CurrencyCAD): "CAD",
+	uint64(CurrencyAUD): "AUD",
+	uint64(CurrencyNZD): "NZD",
+	uint64(CurrencyCNY): "CNY",
+	uint64(CurrencyINR): "INR",
+	uint64(CurrencyCLP): "CLP",
+	uint64(CurrencyPEN): "PEN",
+	uint64(CurrencyCOP): "COP",
+	uint64(CurrencyZAR): "ZAR",
+	uint64(CurrencyHKD): "HKD",
+	uint64(CurrencyTWD): "TWD",
+	uint64(CurrencySAR): "SAR",
+	uint64(CurrencyAED): "AED",
+	uint64(CurrencyARS): "ARS",
+	uint64(CurrencyILS): "ILS",
+	uint64(CurrencyBYN): "BYN",
+	uint64(CurrencyKZT): "KZT",
+	uint64(CurrencyKWD): "KWD",
+	uint64(CurrencyQAR): "QAR",
+	uint64(CurrencyCRC): "CRC",
+	uint64(CurrencyUYU): "UYU",
+	uint64(CurrencyRMB): "RMB",
+}
+
+func (currency Currency) String() string {
+	return currencyNames.format(uint64(currency))
+}
+
+func (currency Currency) MarshalText() ([]byte, error) {
+	return []byte(currency.String()), nil
+}
+
+func (currency *Currency) UnmarshalText(text []byte) error {
+	v, err := currencyNames.parse(text, 32)
+	if err != nil {
+		return err
+	}
+
+	*currency = Currency(v)
+	return nil
+}
+
+// UnmarshalJSON accepts both the numbers sent by Steam and names.
+func (currency *Currency) UnmarshalJSON(data []byte) error {
+	v, ok, err := currencyNames.unmarshalJSON(data, 32)
+	if ok {
+		*currency = Currency(v)
+	}
+
+	return err
+}
+
+// ID is the currency number used in requests.
+func (currency Currency) ID() string {
+	return strconv.FormatUint(uint64(currency), 10)
+}
+
 type MarketItemPriceOverview struct {
 	Success     bool   `json:"success"`
 	LowestPrice string `json:"lowest_price"`
@@ -147,11 +226,11 @@ func (session *Session) GetMarketItemPriceHistory(appID uint64, marketHashName s
 	return items, nil
 }
 
-func (session *Session) GetMarketItemPriceOverview(appID uint64, country, currencyID, marketHashName string) (*MarketItemPriceOverview, error) {
+func (session *Session) GetMarketItemPriceOverview(appID uint64, country string, currency Currency, marketHashName string) (*MarketItemPriceOverview, error) {
 	resp, err := session.client.Get("https://steamcommunity.com/market/priceoverview/?" + url.Values{
 		"appid":            {strconv.FormatUint(appID, 10)},
 		"country":          {country},
-		"currencyID":       {currencyID},
+		"currencyID":       {currency.ID()},
 		"market_hash_name": {marketHashName},
 	}.Encode())
 	if resp != nil {
@@ -203,13 +282,13 @@ func (session *Session) SellItem(item *InventoryItem, amount, price uint64) (*Ma
 	return response, nil
 }
 
-func (session *Session) PlaceBuyOrder(appid uint64, priceTotal float64, quantity uint64, currencyID, marketHashName string) (*MarketBuyOrderResponse, error) {
+func (session *Session) PlaceBuyOrder(appid uint64, priceTotal float64, quantity uint64, currency Currency, marketHashName string) (*MarketBuyOrderResponse, error) {
 	req, err := http.NewRequest(
 		http.MethodPost,
 		"https://steamcommunity.com/market/createbuyorder/",
 		strings.NewReader(url.Values{
 			"appid":            {strconv.FormatUint(appid, 10)},
-			"currency":         {currencyID},
+			"currency":         {currency.ID()},
 			"market_hash_name": {marketHashName},
 			"price_total":      {strconv.FormatUint(uint64(priceTotal*100), 10)},
 			"quantity":         {strconv.FormatUint(quantity, 10)},
@@ -226,7 +305,7 @@ func (session *Session) PlaceBuyOrder(appid uint64, priceTotal float64, quantity
 
 	req.Header.Add(
 		"Referer",
-		fmt.Sprintf("https://steamcommunity.com/market/listings/%d/%s", appid, referer ),
+		fmt.Sprintf("https://steamcommunity.com/market/listings/%d/%s", appid, referer),
 	)
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
@@ -0,0 +1,234 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+type OfferDecision uint8
+
+const (
+	OfferDecisionNone    OfferDecision = iota // no opinion, ask the next rule
+	OfferDecisionHold                         // leave it for manual review
+	OfferDecisionAccept                       // accept it
+	OfferDecisionDecline                      // decline it
+)
+
+func (decision OfferDecision) String() string {
+	switch decision {
+	case OfferDecisionHold:
+		return "hold"
+	case OfferDecisionAccept:
+		return "accept"
+	case OfferDecisionDecline:
+		return "decline"
+	}
+
+	return "none"
+}
+
+// OfferRule decides what to do with a received offer, the returned reason
+// is recorded in the OfferEvaluation.  Rules that cannot reach a verdict
+// because a request failed should hold the offer rather than let the next
+// rule accept it.
+type OfferRule func(ctx context.Context, offer *TradeOffer) (OfferDecision, string)
+
+// AcceptGifts accepts offers which take nothing from us.
+func AcceptGifts() OfferRule {
+	return func(ctx context.Context, offer *TradeOffer) (OfferDecision, string) {
+		if len(offer.SendItems) == 0 && len(offer.RecvItems) != 0 {
+			return OfferDecisionAccept, "gift"
+		}
+
+		return OfferDecisionNone, ""
+	}
+}
+
+// DeclineBannedPartners declines offers from partners with a VAC, community
+// or economy (trade) ban, it needs an API key.
+func DeclineBannedPartners(session *Session) OfferRule {
+	return func(ctx context.Context, offer *TradeOffer) (OfferDecision, string) {
+		var sid SteamID
+		sid.ParseDefaults(offer.Partner)
+
+		bans, err := session.GetPlayerBans(sid.ToString())
+		if err != nil {
+			return OfferDecisionHold, fmt.Sprintf("unable to load partner bans: %v", err)
+		}
+
+		for _, ban := range bans {
+			switch {
+			case ban.VACBanned:
+				return OfferDecisionDecline, "partner is VAC banned"
+			case ban.CommunityBanned:
+				return OfferDecisionDecline, "partner is community banned"
+			case len(ban.EconomyBan) != 0 && ban.EconomyBan != "none":
+				return OfferDecisionDecline, "partner is trade banned (" + ban.EconomyBan + ")"
+			}
+		}
+
+		return OfferDecisionNone, ""
+	}
+}
+
+// DeclineEscrow declines offers whose items would be held, as read from the
+// offer's own page.  Offers whose hold cannot be determined are held.
+func DeclineEscrow(session *Session) OfferRule {
+	return func(ctx context.Context, offer *TradeOffer) (OfferDecision, string) {
+		if offer.EscrowEndDate != 0 {
+			return OfferDecisionDecline, "trade would be held in escrow"
+		}
+
+		info, err := session.GetTradeOfferEscrowInfo(offer.ID)
+		if err != nil {
+			return OfferDecisionHold, fmt.Sprintf("unable to load escrow days: %v", err)
+		}
+
+		if len(info.ErrorMsg) != 0 {
+			return OfferDecisionHold, "unable to load escrow days: " + info.ErrorMsg
+		}
+
+		if info.MyDays != 0 || info.ThemDays != 0 {
+			return OfferDecisionDecline, fmt.Sprintf("trade would be held (%d days for us, %d for them)", info.MyDays, info.ThemDays)
+		}
+
+		return OfferDecisionNone, ""
+	}
+}
+
+// DeclineBelowValueRatio declines offers where what we receive is worth less
+// than @ratio times what we give, priced in @currency.  Offers with items
+// that could not be priced are held.
+func DeclineBelowValueRatio(pricer Pricer, currency Currency, ratio float64) OfferRule {
+	return func(ctx context.Context, offer *TradeOffer) (OfferDecision, string) {
+		value, err := offer.Value(ctx, pricer, currency)
+		if err != nil {
+			return OfferDecisionHold, fmt.Sprintf("unable to value offer: %v", err)
+		}
+
+		if !value.Complete {
+			return OfferDecisionHold, "unable to price every item"
+		}
+
+		if value.GiveTotal == 0 {
+			return OfferDecisionNone, ""
+		}
+
+		if got := value.ReceiveTotal / value.GiveTotal; got < ratio {
+			return OfferDecisionDecline, fmt.Sprintf("value ratio %.2f is below %.2f (give %.2f %s, receive %.2f %s)",
+				got, ratio, value.GiveTotal, currency, value.ReceiveTotal, currency)
+		}
+
+		return OfferDecisionNone, ""
+	}
+}
+
+// OfferEvaluation records what was decided about an offer and why.
+type OfferEvaluation struct {
+	Offer    *TradeOffer
+	Decision OfferDecision
+	Rule     int // index of the rule in Rules which decided, -1 if none did
+	Reason   string
+	Result   *AcceptResult // set when the offer was accepted
+	Err      error         // set when acting on the decision failed
+}
+
+// OfferEvaluator asks Rules in order what to do with received offers, the
+// first rule with an opinion wins and offers no rule has an opinion on are
+// held.  Accepted trades are confirmed when an identity secret is given.
+// Every decision is written to Logger (the standard logger if nil).
+//
+// Set it as TradeOfferManager.Evaluator to handle offers as they come in:
+//
+//	evaluator := steam.NewOfferEvaluator(session, identitySecret)
+//	evaluator.Rules = []steam.OfferRule{
+//		steam.AcceptGifts(),
+//		steam.DeclineBannedPartners(session),
+//		steam.DeclineEscrow(session),
+//		steam.DeclineBelowValueRatio(pricer, steam.CurrencyUSD, 1.0),
+//	}
+type OfferEvaluator struct {
+	Rules  []OfferRule
+	Logger *log.Logger
+
+	session        *Session
+	identitySecret string
+}
+
+func NewOfferEvaluator(session *Session, identitySecret string) *OfferEvaluator {
+	return &OfferEvaluator{
+		session:        session,
+		identitySecret: identitySecret,
+	}
+}
+
+func (evaluator *OfferEvaluator) logf(format string, v ...interface{}) {
+	if evaluator.Logger != nil {
+		evaluator.Logger.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
+// Evaluate decides what to do with @offer without acting on it.
+func (evaluator *OfferEvaluator) Evaluate(ctx context.Context, offer *TradeOffer) *OfferEvaluation {
+	evaluation := &OfferEvaluation{
+		Offer:    offer,
+		Decision: OfferDecisionHold,
+		Rule:     -1,
+		Reason:   "no rule matched",
+	}
+
+	if offer.IsOurOffer {
+		evaluation.Reason = "offer was sent by us"
+		return evaluation
+	}
+
+	if offer.State != TradeStateActive {
+		evaluation.Reason = "offer is " + offer.State.String()
+		return evaluation
+	}
+
+	for i, rule := range evaluator.Rules {
+		if decision, reason := rule(ctx, offer); decision != OfferDecisionNone {
+			evaluation.Decision = decision
+			evaluation.Rule = i
+			evaluation.Reason = reason
+			break
+		}
+	}
+
+	return evaluation
+}
+
+// Handle evaluates @offer and accepts or declines it accordingly.
+func (evaluator *OfferEvaluator) Handle(ctx context.Context, offer *TradeOffer) *OfferEvaluation {
+	evaluation := evaluator.Evaluate(ctx, offer)
+
+	switch evaluation.Decision {
+	case OfferDecisionAccept:
+		// The offer may have changed since it was polled.
+		if _, err := evaluator.session.CheckTradeOffer(offer); err != nil {
+			evaluation.Decision = OfferDecisionHold
+			evaluation.Reason = "pre-accept check failed: " + err.Error()
+			break
+		}
+
+		if len(evaluator.identitySecret) != 0 {
+			evaluation.Result, evaluation.Err = evaluator.session.AcceptTradeOfferAndConfirm(offer.ID, evaluator.identitySecret)
+		} else {
+			evaluation.Result, evaluation.Err = evaluator.session.AcceptTradeOffer(offer.ID)
+		}
+	case OfferDecisionDecline:
+		evaluation.Err = evaluator.session.DeclineTradeOffer(offer.ID)
+	}
+
+	evaluator.logf("evaluator: %s trade offer %d (partner %d, rule %d): %s",
+		evaluation.Decision, offer.ID, offer.Partner, evaluation.Rule, evaluation.Reason)
+	if evaluation.Err != nil {
+		evaluator.logf("evaluator: unable to %s trade offer %d: %v", evaluation.Decision, offer.ID, evaluation.Err)
+	}
+
+	return evaluation
+}
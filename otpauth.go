@@ -0,0 +1,155 @@
+package steam
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	otpAuthScheme = "otpauth"
+	otpAuthIssuer = "Steam"
+	otpAuthDigits = 5
+	otpAuthPeriod = 30
+)
+
+var (
+	ErrInvalidOTPAuthURI     = errors.New("invalid otpauth URI")
+	ErrUnsupportedOTPAuthURI = errors.New("otpauth URI is not a Steam authenticator")
+	ErrInvalidSharedSecret   = errors.New("shared secret is not valid base64")
+)
+
+// OTPAuth is the content of an otpauth:// URI, e.g.
+//
+//	otpauth://totp/Steam:account?secret=BASE32&issuer=Steam
+//
+// The secret is kept base64-encoded, which is the form GenerateTwoFactorCode
+// and TwoFactorInfo.SharedSecret use, rather than the base32 of the URI.
+type OTPAuth struct {
+	Issuer       string
+	AccountName  string
+	SharedSecret string
+}
+
+// NewOTPAuth returns ErrInvalidSharedSecret if @sharedSecret is not base64.
+func NewOTPAuth(accountName, sharedSecret string) (*OTPAuth, error) {
+	if _, err := base64.StdEncoding.DecodeString(sharedSecret); err != nil {
+		return nil, ErrInvalidSharedSecret
+	}
+
+	return &OTPAuth{
+		Issuer:       otpAuthIssuer,
+		AccountName:  accountName,
+		SharedSecret: sharedSecret,
+	}, nil
+}
+
+// ParseOTPAuthURI parses URIs as produced by Steam itself and by generic
+// authenticator exports, which either use the "steam" type or mark the entry
+// with the Steam issuer, 5 digits or encoder=steam.  Anything that cannot be
+// fed to GenerateTwoFactorCode is rejected with ErrUnsupportedOTPAuthURI.
+func ParseOTPAuthURI(uri string) (*OTPAuth, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != otpAuthScheme {
+		return nil, ErrInvalidOTPAuthURI
+	}
+
+	otpType := strings.ToLower(u.Host)
+	if otpType != "totp" && otpType != "steam" {
+		return nil, ErrUnsupportedOTPAuthURI
+	}
+
+	query := u.Query()
+	secret := strings.ToUpper(strings.TrimRight(strings.Replace(query.Get("secret"), " ", "", -1), "="))
+	if len(secret) == 0 {
+		return nil, ErrInvalidOTPAuthURI
+	}
+
+	data, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, ErrInvalidOTPAuthURI
+	}
+
+	auth := &OTPAuth{
+		Issuer:       query.Get("issuer"),
+		SharedSecret: base64.StdEncoding.EncodeToString(data),
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(label, ":"); i != -1 {
+		if len(auth.Issuer) == 0 {
+			auth.Issuer = label[:i]
+		}
+		label = label[i+1:]
+	}
+	auth.AccountName = strings.TrimSpace(label)
+
+	if algorithm := query.Get("algorithm"); len(algorithm) != 0 && !strings.EqualFold(algorithm, "SHA1") {
+		return nil, ErrUnsupportedOTPAuthURI
+	}
+
+	if period := query.Get("period"); len(period) != 0 && period != strconv.Itoa(otpAuthPeriod) {
+		return nil, ErrUnsupportedOTPAuthURI
+	}
+
+	isSteam := otpType == "steam" ||
+		strings.EqualFold(auth.Issuer, otpAuthIssuer) ||
+		strings.EqualFold(query.Get("encoder"), "steam") ||
+		query.Get("digits") == strconv.Itoa(otpAuthDigits)
+	if !isSteam {
+		return nil, ErrUnsupportedOTPAuthURI
+	}
+
+	return auth, nil
+}
+
+// URI formats the URI the way Steam does, plus the digits and encoder hints
+// generic authenticators use to produce Steam's 5 character codes.
+func (auth *OTPAuth) URI() (string, error) {
+	issuer := auth.Issuer
+	if len(issuer) == 0 {
+		issuer = otpAuthIssuer
+	}
+
+	data, err := base64.StdEncoding.DecodeString(auth.SharedSecret)
+	if err != nil {
+		return "", ErrInvalidSharedSecret
+	}
+
+	u := url.URL{
+		Scheme: otpAuthScheme,
+		Host:   "totp",
+		Path:   "/" + issuer + ":" + auth.AccountName,
+		RawQuery: url.Values{
+			"secret":  {base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(data)},
+			"issuer":  {issuer},
+			"digits":  {strconv.Itoa(otpAuthDigits)},
+			"encoder": {"steam"},
+		}.Encode(),
+	}
+	return u.String(), nil
+}
+
+// String returns the URI, or an empty string if the shared secret is invalid.
+// Use URI when the error matters.
+func (auth *OTPAuth) String() string {
+	uri, _ := auth.URI()
+	return uri
+}
+
+// OTPAuth parses the URI Steam returned along with the secrets, if it is
+// missing one is built from the shared secret and account name.
+func (info *TwoFactorInfo) OTPAuth() (*OTPAuth, error) {
+	if len(info.URI) == 0 {
+		return NewOTPAuth(info.AccountName, info.SharedSecret)
+	}
+
+	return ParseOTPAuthURI(info.URI)
+}
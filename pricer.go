@@ -0,0 +1,303 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrItemNoMarketName = errors.New("item has no description or market hash name")
+	ErrCannotParsePrice = errors.New("unable to parse market price")
+	ErrNoMarketPrice    = errors.New("no market price in the requested currency")
+	ErrAmbiguousPrice   = errors.New("market price separator is ambiguous without a currency")
+)
+
+// Pricer returns the market value of a single unit of an item.
+type Pricer interface {
+	Price(ctx context.Context, appID uint32, marketHashName string, currency Currency) (float64, error)
+}
+
+// marketPriceDecimals returns how many decimals the market shows for
+// @currency, 0 when the currency is not known.
+func marketPriceDecimals(currency Currency) int {
+	switch currency {
+	case 0:
+		return 0
+	case CurrencyKWD:
+		return 3
+	}
+
+	return 2
+}
+
+// parseMarketPrice parses prices as formatted by the market in @currency,
+// e.g. "$1,234.56", "1.234,56€", "12,34 pуб." or "1.234 KD".  Pass 0 as
+// @currency when it is not known, a lone separator followed by 3 digits
+// then returns ErrAmbiguousPrice since it could be either.
+func parseMarketPrice(price string, currency Currency) (float64, error) {
+	digits := make([]byte, 0, len(price))
+	for i := 0; i < len(price); i++ {
+		if c := price[i]; (c >= '0' && c <= '9') || c == '.' || c == ',' {
+			digits = append(digits, c)
+		}
+	}
+
+	s := strings.Trim(string(digits), ".,")
+	if len(s) == 0 {
+		return 0, ErrCannotParsePrice
+	}
+
+	// A separator used more than once is the thousands one, a last separator
+	// after a different one is the decimal one.  A lone separator is the
+	// decimal one if followed by no more digits than the currency shows.
+	decimals := ""
+	if i := strings.LastIndexAny(s, ".,"); i != -1 && strings.IndexByte(s[:i], s[i]) == -1 {
+		n := len(s) - i - 1
+		switch {
+		case strings.ContainsAny(s[:i], ".,"):
+			s, decimals = s[:i], s[i+1:]
+		case n <= 2 || n <= marketPriceDecimals(currency):
+			s, decimals = s[:i], s[i+1:]
+		case n == 3 && currency == 0:
+			return 0, ErrAmbiguousPrice
+		}
+	}
+
+	s = strings.NewReplacer(".", "", ",", "").Replace(s)
+	if len(decimals) != 0 {
+		s += "." + decimals
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrCannotParsePrice
+	}
+
+	return v, nil
+}
+
+// MarketPricer prices items with GetMarketItemPriceOverview, using the
+// lowest listing and falling back to the median price.  When the overview
+// has no price at all the latest entry of GetMarketItemPriceHistory is used,
+// but only if the requested currency is @walletCurrency since the history is
+// in the session's wallet currency, ErrNoMarketPrice is returned otherwise.
+// Pass 0 as @walletCurrency to never use the history.
+type MarketPricer struct {
+	session        *Session
+	country        string
+	walletCurrency Currency
+}
+
+func NewMarketPricer(session *Session, country string, walletCurrency Currency) *MarketPricer {
+	return &MarketPricer{
+		session:        session,
+		country:        country,
+		walletCurrency: walletCurrency,
+	}
+}
+
+func (pricer *MarketPricer) Price(ctx context.Context, appID uint32, marketHashName string, currency Currency) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	overview, err := pricer.session.GetMarketItemPriceOverview(uint64(appID), pricer.country, currency, marketHashName)
+	if err != nil {
+		return 0, err
+	}
+
+	if overview.Success {
+		if price, err := parseMarketPrice(overview.LowestPrice, currency); err == nil {
+			return price, nil
+		}
+
+		if price, err := parseMarketPrice(overview.MedianPrice, currency); err == nil {
+			return price, nil
+		}
+	}
+
+	if pricer.walletCurrency == 0 || pricer.walletCurrency != currency {
+		return 0, ErrNoMarketPrice
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	history, err := pricer.session.GetMarketItemPriceHistory(uint64(appID), marketHashName)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(history) == 0 {
+		return 0, ErrCannotLoadPrices
+	}
+
+	return history[len(history)-1].Price, nil
+}
+
+// CachedPricer remembers prices returned by another Pricer for TTL, in
+// memory and, if set with SetStore, in a Store shared between restarts.
+// Failing to save a price to the Store does not fail Price, it is written to
+// Logger (the standard logger if nil) instead.
+type CachedPricer struct {
+	Logger *log.Logger
+
+	pricer Pricer
+	ttl    time.Duration
+	store  Store
+
+	mutex sync.Mutex
+	cache map[string]*StoredPrice
+}
+
+func NewCachedPricer(pricer Pricer, ttl time.Duration) *CachedPricer {
+	return &CachedPricer{
+		pricer: pricer,
+		ttl:    ttl,
+		cache:  make(map[string]*StoredPrice),
+	}
+}
+
+func (pricer *CachedPricer) SetStore(store Store) {
+	pricer.mutex.Lock()
+	pricer.store = store
+	pricer.mutex.Unlock()
+}
+
+func (pricer *CachedPricer) logf(format string, v ...interface{}) {
+	if pricer.Logger != nil {
+		pricer.Logger.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
+func priceKey(appID uint32, marketHashName string, currency Currency) string {
+	return strconv.FormatUint(uint64(appID), 10) + "_" + currency.ID() + "_" + marketHashName
+}
+
+func (pricer *CachedPricer) Price(ctx context.Context, appID uint32, marketHashName string, currency Currency) (float64, error) {
+	key := priceKey(appID, marketHashName, currency)
+
+	pricer.mutex.Lock()
+	cached, ok := pricer.cache[key]
+	store := pricer.store
+	pricer.mutex.Unlock()
+
+	if !ok && store != nil {
+		if stored, err := store.LoadPrice(key); err == nil {
+			cached, ok = stored, true
+		}
+	}
+
+	if ok && time.Now().Before(cached.Expires) {
+		return cached.Price, nil
+	}
+
+	price, err := pricer.pricer.Price(ctx, appID, marketHashName, currency)
+	if err != nil {
+		return 0, err
+	}
+
+	cached = &StoredPrice{Price: price, Expires: time.Now().Add(pricer.ttl)}
+
+	pricer.mutex.Lock()
+	pricer.cache[key] = cached
+	pricer.mutex.Unlock()
+
+	if store != nil {
+		if err = store.SavePrice(key, cached); err != nil {
+			pricer.logf("pricer: unable to store price of %s: %v", key, err)
+		}
+	}
+
+	return price, nil
+}
+
+// ItemValue is the value of one trade offer item, Err is set when it could
+// not be priced in which case it does not count towards the totals.
+type ItemValue struct {
+	Item  *EconItem
+	Price float64 // per unit
+	Value float64 // Price times amount
+	Err   error
+}
+
+type TradeOfferValue struct {
+	Currency     Currency
+	Give         []*ItemValue
+	Receive      []*ItemValue
+	GiveTotal    float64
+	ReceiveTotal float64
+	Profit       float64 // ReceiveTotal - GiveTotal
+	Complete     bool    // every item could be priced
+}
+
+func priceItems(ctx context.Context, pricer Pricer, currency Currency, items []*EconItem) ([]*ItemValue, float64, bool, error) {
+	values := make([]*ItemValue, len(items))
+	total := float64(0)
+	complete := true
+
+	for i, item := range items {
+		value := &ItemValue{Item: item}
+		values[i] = value
+
+		if item.Desc == nil || len(item.Desc.MarketHashName) == 0 {
+			value.Err = ErrItemNoMarketName
+			complete = false
+			continue
+		}
+
+		price, err := pricer.Price(ctx, item.AppID, item.Desc.MarketHashName, currency)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, 0, false, ctxErr
+			}
+
+			value.Err = err
+			complete = false
+			continue
+		}
+
+		amount := float64(item.Amount)
+		if amount == 0 {
+			amount = 1
+		}
+
+		value.Price = price
+		value.Value = price * amount
+		total += value.Value
+	}
+
+	return values, total, complete, nil
+}
+
+// Value estimates what the offer is worth in @currency using the item
+// descriptions (see GetTradeOffer), an error is only returned when @ctx is done.
+func (offer *TradeOffer) Value(ctx context.Context, pricer Pricer, currency Currency) (*TradeOfferValue, error) {
+	give, giveTotal, giveComplete, err := priceItems(ctx, pricer, currency, offer.SendItems)
+	if err != nil {
+		return nil, err
+	}
+
+	receive, receiveTotal, receiveComplete, err := priceItems(ctx, pricer, currency, offer.RecvItems)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TradeOfferValue{
+		Currency:     currency,
+		Give:         give,
+		Receive:      receive,
+		GiveTotal:    giveTotal,
+		ReceiveTotal: receiveTotal,
+		Profit:       receiveTotal - giveTotal,
+		Complete:     giveComplete && receiveComplete,
+	}, nil
+}
@@ -9,12 +9,48 @@ import (
 	"strconv"
 )
 
+type PrivacyState uint8
+
 const (
-	PrivacyStatePrivate     = 1
-	PrivacyStateFriendsOnly = 2
-	PrivacyStatePublic      = 3
+	PrivacyStatePrivate     PrivacyState = 1
+	PrivacyStateFriendsOnly PrivacyState = 2
+	PrivacyStatePublic      PrivacyState = 3
 )
 
+var privacyStateNames = enumNames{
+	uint64(PrivacyStatePrivate):     "Private",
+	uint64(PrivacyStateFriendsOnly): "FriendsOnly",
+	uint64(PrivacyStatePublic):      "Public",
+}
+
+func (state PrivacyState) String() string {
+	return privacyStateNames.format(uint64(state))
+}
+
+func (state PrivacyState) MarshalText() ([]byte, error) {
+	return []byte(state.String()), nil
+}
+
+func (state *PrivacyState) UnmarshalText(text []byte) error {
+	v, err := privacyStateNames.parse(text, 8)
+	if err != nil {
+		return err
+	}
+
+	*state = PrivacyState(v)
+	return nil
+}
+
+// UnmarshalJSON accepts both the numbers sent by Steam and names.
+func (state *PrivacyState) UnmarshalJSON(data []byte) error {
+	v, ok, err := privacyStateNames.unmarshalJSON(data, 8)
+	if ok {
+		*state = PrivacyState(v)
+	}
+
+	return err
+}
+
 const (
 	CommentSettingSelf    = "commentselfonly"
 	CommentSettingFriends = "commentfriendsonly"
@@ -32,26 +68,26 @@ const (
 var ErrCannotFindVanityMatch = errors.New("no match for the vanity URL")
 
 type PlayerSummary struct {
-	SteamID           SteamID `json:"steamid,string"`
-	VisibilityState   uint32  `json:"communityvisibilitystate"`
-	ProfileState      uint32  `json:"profilestate"`
-	PersonaName       string  `json:"personaname"`
-	PersonaState      uint32  `json:"personastate"`
-	PersonaStateFlags uint32  `json:"personastateflags"`
-	RealName          string  `json:"realname"`
-	LastLogoff        int64   `json:"lastlogoff"`
-	ProfileURL        string  `json:"profileurl"`
-	AvatarURL         string  `json:"avatar"`
-	AvatarMediumURL   string  `json:"avatarmedium"`
-	AvatarFullURL     string  `json:"avatarfull"`
-	PrimaryClanID     uint64  `json:"primaryclanid,string"`
-	TimeCreated       int64   `json:"timecreated"`
-	LocCountryCode    string  `json:"loccountrycode"`
-	LocStateCode      string  `json:"locstatecode"`
-	LocCityID         uint32  `json:"loccityid"`
-	GameID            uint64  `json:"gameid,string"`
-	GameServerIP      string  `json:"gameserverip"`
-	GameExtraInfo     string  `json:"gameextrainfo"`
+	SteamID           SteamID      `json:"steamid,string"`
+	VisibilityState   PrivacyState `json:"communityvisibilitystate"`
+	ProfileState      uint32       `json:"profilestate"`
+	PersonaName       string       `json:"personaname"`
+	PersonaState      PersonaState `json:"personastate"`
+	PersonaStateFlags uint32       `json:"personastateflags"`
+	RealName          string       `json:"realname"`
+	LastLogoff        int64        `json:"lastlogoff"`
+	ProfileURL        string       `json:"profileurl"`
+	AvatarURL         string       `json:"avatar"`
+	AvatarMediumURL   string       `json:"avatarmedium"`
+	AvatarFullURL     string       `json:"avatarfull"`
+	PrimaryClanID     uint64       `json:"primaryclanid,string"`
+	TimeCreated       int64        `json:"timecreated"`
+	LocCountryCode    string       `json:"loccountrycode"`
+	LocStateCode      string       `json:"locstatecode"`
+	LocCityID         uint32       `json:"loccityid"`
+	GameID            uint64       `json:"gameid,string"`
+	GameServerIP      string       `json:"gameserverip"`
+	GameExtraInfo     string       `json:"gameextrainfo"`
 }
 
 type Game struct {
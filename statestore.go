@@ -0,0 +1,359 @@
+package steam
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrNotStored = errors.New("not found in store")
+
+// ConfirmerState is what a Confirmer needs to carry on after a restart.
+type ConfirmerState struct {
+	Offers   map[uint64]*TradeOffer          `json:"offers"`   // expected trade offers by offer ID
+	Listings []*ExpectedListing              `json:"listings"` // expected market listings
+	Handled  map[uint64]ConfirmationDecision `json:"handled"`  // confirmations answered, by confirmation ID
+}
+
+type InventorySnapshot struct {
+	SteamID   SteamID         `json:"steamid,string"`
+	AppID     uint64          `json:"appid"`
+	ContextID uint64          `json:"contextid,string"`
+	Taken     time.Time       `json:"taken"`
+	Items     []InventoryItem `json:"items"`
+}
+
+type StoredPrice struct {
+	Price   float64   `json:"price"`
+	Expires time.Time `json:"expires"`
+}
+
+// Store keeps state across restarts, Load methods return ErrNotStored when
+// there is nothing saved under the given key.  Values are copied in and out,
+// changing them after saving does not change what is stored.
+type Store interface {
+	SaveTradeOffer(offer *TradeOffer) error
+	LoadTradeOffer(id uint64) (*TradeOffer, error)
+	SavePollData(data *TradeOfferPollData) error
+	LoadPollData() (*TradeOfferPollData, error)
+	SaveConfirmerState(state *ConfirmerState) error
+	LoadConfirmerState() (*ConfirmerState, error)
+	SaveInventory(snapshot *InventorySnapshot) error
+	LoadInventory(sid SteamID, appID, contextID uint64) (*InventorySnapshot, error)
+	SavePrice(key string, price *StoredPrice) error
+	LoadPrice(key string) (*StoredPrice, error)
+}
+
+const (
+	storeKindOffer     = "offer"
+	storeKindPollData  = "polldata"
+	storeKindConfirmer = "confirmer"
+	storeKindInventory = "inventory"
+	storeKindPrice     = "price"
+)
+
+type storeRecord struct {
+	Kind  string          `json:"kind"`
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// MemoryStore keeps everything JSON encoded in memory.
+type MemoryStore struct {
+	mutex   sync.Mutex
+	records map[string]map[string]json.RawMessage
+
+	// persist is called with every record saved while the mutex is held.
+	persist func(record *storeRecord) error
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]map[string]json.RawMessage),
+	}
+}
+
+func (store *MemoryStore) set(record *storeRecord) {
+	records, ok := store.records[record.Kind]
+	if !ok {
+		records = make(map[string]json.RawMessage)
+		store.records[record.Kind] = records
+	}
+
+	records[record.Key] = record.Value
+}
+
+func (store *MemoryStore) save(kind, key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	record := &storeRecord{Kind: kind, Key: key, Value: value}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.persist != nil {
+		if err = store.persist(record); err != nil {
+			return err
+		}
+	}
+
+	store.set(record)
+	return nil
+}
+
+func (store *MemoryStore) load(kind, key string, v interface{}) error {
+	store.mutex.Lock()
+	value, ok := store.records[kind][key]
+	store.mutex.Unlock()
+
+	if !ok {
+		return ErrNotStored
+	}
+
+	return json.Unmarshal(value, v)
+}
+
+func (store *MemoryStore) SaveTradeOffer(offer *TradeOffer) error {
+	return store.save(storeKindOffer, strconv.FormatUint(offer.ID, 10), offer)
+}
+
+func (store *MemoryStore) LoadTradeOffer(id uint64) (*TradeOffer, error) {
+	offer := &TradeOffer{}
+	if err := store.load(storeKindOffer, strconv.FormatUint(id, 10), offer); err != nil {
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+func (store *MemoryStore) SavePollData(data *TradeOfferPollData) error {
+	return store.save(storeKindPollData, "", data)
+}
+
+func (store *MemoryStore) LoadPollData() (*TradeOfferPollData, error) {
+	data := &TradeOfferPollData{}
+	if err := store.load(storeKindPollData, "", data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (store *MemoryStore) SaveConfirmerState(state *ConfirmerState) error {
+	return store.save(storeKindConfirmer, "", state)
+}
+
+func (store *MemoryStore) LoadConfirmerState() (*ConfirmerState, error) {
+	state := &ConfirmerState{}
+	if err := store.load(storeKindConfirmer, "", state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func inventoryKey(sid SteamID, appID, contextID uint64) string {
+	return sid.ToString() + "_" + strconv.FormatUint(appID, 10) + "_" + strconv.FormatUint(contextID, 10)
+}
+
+func (store *MemoryStore) SaveInventory(snapshot *InventorySnapshot) error {
+	return store.save(storeKindInventory, inventoryKey(snapshot.SteamID, snapshot.AppID, snapshot.ContextID), snapshot)
+}
+
+func (store *MemoryStore) LoadInventory(sid SteamID, appID, contextID uint64) (*InventorySnapshot, error) {
+	snapshot := &InventorySnapshot{}
+	if err := store.load(storeKindInventory, inventoryKey(sid, appID, contextID), snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func (store *MemoryStore) SavePrice(key string, price *StoredPrice) error {
+	return store.save(storeKindPrice, key, price)
+}
+
+func (store *MemoryStore) LoadPrice(key string) (*StoredPrice, error) {
+	price := &StoredPrice{}
+	if err := store.load(storeKindPrice, key, price); err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
+
+// GetInventorySnapshot returns the inventory saved in @store if it is younger
+// than @maxAge, otherwise it loads it with GetInventory and saves it.
+func (session *Session) GetInventorySnapshot(store Store, sid SteamID, appID, contextID uint64, maxAge time.Duration) (*InventorySnapshot, error) {
+	snapshot, err := store.LoadInventory(sid, appID, contextID)
+	if err == nil && time.Since(snapshot.Taken) < maxAge {
+		return snapshot, nil
+	} else if err != nil && err != ErrNotStored {
+		return nil, err
+	}
+
+	items, err := session.GetInventory(sid, appID, contextID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot = &InventorySnapshot{
+		SteamID:   sid,
+		AppID:     appID,
+		ContextID: contextID,
+		Taken:     time.Now(),
+		Items:     items,
+	}
+
+	if err = store.SaveInventory(snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// FileStore is a MemoryStore which appends every save to a JSON lines file
+// and replays it when opened, the last record for a key wins.  Compact
+// rewrites the file with only the current records.
+type FileStore struct {
+	*MemoryStore
+
+	path string
+	file *os.File
+}
+
+func OpenFileStore(path string) (*FileStore, error) {
+	store := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		path:        path,
+	}
+
+	if err := store.replay(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	store.file = file
+	store.persist = store.append
+	return store, nil
+}
+
+// replay loads the records of the file.  A crash in the middle of a write
+// leaves a partial last line, it is cut off so the next record starts on a
+// line of its own, anything unreadable before it is an error.
+func (store *FileStore) replay() error {
+	file, err := os.OpenFile(store.path, os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	offset := int64(0)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) != 0 {
+				// Partial last line.
+				return file.Truncate(offset)
+			}
+
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		record := &storeRecord{}
+		if len(bytes.TrimSpace(line)) != 0 {
+			if jsonErr := json.Unmarshal(line, record); jsonErr != nil {
+				if _, err = reader.Peek(1); err == io.EOF {
+					return file.Truncate(offset)
+				}
+
+				return fmt.Errorf("%s: corrupt record at offset %d: %v", store.path, offset, jsonErr)
+			}
+
+			store.set(record)
+		}
+
+		offset += int64(len(line))
+	}
+}
+
+func (store *FileStore) append(record *storeRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.file.Write(append(line, '\n'))
+	return err
+}
+
+// Compact replaces the file with one holding a single record per key.
+func (store *FileStore) Compact() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	tmp := store.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for kind, records := range store.records {
+		for key, value := range records {
+			if err = encoder.Encode(&storeRecord{Kind: kind, Key: key, Value: value}); err != nil {
+				file.Close()
+				return err
+			}
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp, store.path); err != nil {
+		return err
+	}
+
+	store.file.Close()
+	store.file, err = os.OpenFile(store.path, os.O_WRONLY|os.O_APPEND, 0600)
+	return err
+}
+
+func (store *FileStore) Close() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.file.Close()
+}
@@ -0,0 +1,338 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type TradeStatus uint8
+
+const (
+	TradeStatusInit TradeStatus = iota
+	TradeStatusPreCommitted
+	TradeStatusCommitted
+	TradeStatusComplete
+	TradeStatusFailed
+	TradeStatusPartialSupportRollback
+	TradeStatusFullSupportRollback
+	TradeStatusSupportRollbackSelective
+	TradeStatusRollbackFailed
+	TradeStatusRollbackAbandoned
+	TradeStatusInEscrow
+	TradeStatusEscrowRollback
+	TradeStatusTradeProtectionRollback // reverted during trade protection
+)
+
+var tradeStatusNames = enumNames{
+	uint64(TradeStatusInit):                     "Init",
+	uint64(TradeStatusPreCommitted):             "PreCommitted",
+	uint64(TradeStatusCommitted):                "Committed",
+	uint64(TradeStatusComplete):                 "Complete",
+	uint64(TradeStatusFailed):                   "Failed",
+	uint64(TradeStatusPartialSupportRollback):   "PartialSupportRollback",
+	uint64(TradeStatusFullSupportRollback):      "FullSupportRollback",
+	uint64(TradeStatusSupportRollbackSelective): "SupportRollbackSelective",
+	uint64(TradeStatusRollbackFailed):           "RollbackFailed",
+	uint64(TradeStatusRollbackAbandoned):        "RollbackAbandoned",
+	uint64(TradeStatusInEscrow):                 "InEscrow",
+	uint64(TradeStatusEscrowRollback):           "EscrowRollback",
+	uint64(TradeStatusTradeProtectionRollback):  "TradeProtectionRollback",
+}
+
+func (status TradeStatus) String() string {
+	return tradeStatusNames.format(uint64(status))
+}
+
+func (status TradeStatus) MarshalText() ([]byte, error) {
+	return []byte(status.String()), nil
+}
+
+func (status *TradeStatus) UnmarshalText(text []byte) error {
+	v, err := tradeStatusNames.parse(text, 8)
+	if err != nil {
+		return err
+	}
+
+	*status = TradeStatus(v)
+	return nil
+}
+
+// UnmarshalJSON accepts both the numbers sent by Steam and names.
+func (status *TradeStatus) UnmarshalJSON(data []byte) error {
+	v, ok, err := tradeStatusNames.unmarshalJSON(data, 8)
+	if ok {
+		*status = TradeStatus(v)
+	}
+
+	return err
+}
+
+const (
+	apiGetTradeOffersSummary = "https://api.steampowered.com/IEconService/GetTradeOffersSummary/v1/?"
+	apiGetTradeHistory       = "https://api.steampowered.com/IEconService/GetTradeHistory/v1/?"
+	apiGetTradeStatus        = "https://api.steampowered.com/IEconService/GetTradeStatus/v1/?"
+)
+
+var ErrTradeNotFound = errors.New("trade not found")
+
+type TradeOffersSummary struct {
+	PendingReceivedCount    uint32 `json:"pending_received_count"`
+	NewReceivedCount        uint32 `json:"new_received_count"`
+	UpdatedReceivedCount    uint32 `json:"updated_received_count"`
+	HistoricalReceivedCount uint32 `json:"historical_received_count"`
+	PendingSentCount        uint32 `json:"pending_sent_count"`
+	NewlyAcceptedSentCount  uint32 `json:"newly_accepted_sent_count"`
+	UpdatedSentCount        uint32 `json:"updated_sent_count"`
+	HistoricalSentCount     uint32 `json:"historical_sent_count"`
+	EscrowReceivedCount     uint32 `json:"escrow_received_count"`
+	EscrowSentCount         uint32 `json:"escrow_sent_count"`
+}
+
+// TradeAsset is an item which changed hands in a completed trade, the item
+// lives under NewAssetID/NewContextID in the receiving inventory.
+type TradeAsset struct {
+	AppID        uint32        `json:"appid"`
+	ContextID    uint64        `json:"contextid,string"`
+	AssetID      uint64        `json:"assetid,string"`
+	Amount       uint64        `json:"amount,string"`
+	ClassID      uint64        `json:"classid,string"`
+	InstanceID   uint64        `json:"instanceid,string"`
+	NewAssetID   uint64        `json:"new_assetid,string"`
+	NewContextID uint64        `json:"new_contextid,string"`
+	Desc         *EconItemDesc `json:"-"` /* May be nil  */
+}
+
+type Trade struct {
+	ID             uint64        `json:"tradeid,string"`
+	Partner        SteamID       `json:"steamid_other,string"`
+	Created        int64         `json:"time_init"`
+	EscrowEndDate  int64         `json:"time_escrow_end"`
+	Status         TradeStatus   `json:"status"`
+	AssetsReceived []*TradeAsset `json:"assets_received"`
+	AssetsGiven    []*TradeAsset `json:"assets_given"`
+
+	// Trades made under trade protection settle at SettlementDate (unix time),
+	// until then the items are protected and the trade can be reverted.
+	// TODO: Reverting is not implemented, Steam's endpoint for it is not
+	// documented and could not be verified.  Reversals done from Steam show
+	// up through GetTradeStatus, see Settlement and IsReverted.
+	DelaySettlement bool  `json:"delay_settlement"`
+	SettlementDate  int64 `json:"time_settlement"`
+}
+
+// TradeSettlement is how far a trade is from being final with regard to
+// trade protection, it is worked out from the trade rather than sent by Steam.
+type TradeSettlement uint8
+
+const (
+	TradeSettlementPending      TradeSettlement = iota // not complete yet
+	TradeSettlementInProtection                        // complete, can still be reverted
+	TradeSettlementSettled                             // complete and final
+	TradeSettlementReverted                            // items went back to their owners
+	TradeSettlementFailed                              // never went through
+)
+
+func (settlement TradeSettlement) String() string {
+	switch settlement {
+	case TradeSettlementInProtection:
+		return "in protection"
+	case TradeSettlementSettled:
+		return "settled"
+	case TradeSettlementReverted:
+		return "reverted"
+	case TradeSettlementFailed:
+		return "failed"
+	}
+
+	return "pending"
+}
+
+// Settlement tells where @trade stands at @now, which should be Steam time,
+// see TradeSettlement on Session.  A delayed trade without a SettlementDate
+// is taken as still in protection since it cannot be known to be final.
+func (trade *Trade) Settlement(now time.Time) TradeSettlement {
+	if trade.IsReverted() {
+		return TradeSettlementReverted
+	}
+
+	switch trade.Status {
+	case TradeStatusComplete:
+		if trade.DelaySettlement && (trade.SettlementDate == 0 || trade.SettlementDate > now.Unix()) {
+			return TradeSettlementInProtection
+		}
+
+		return TradeSettlementSettled
+	case TradeStatusFailed, TradeStatusRollbackFailed, TradeStatusRollbackAbandoned:
+		return TradeSettlementFailed
+	}
+
+	return TradeSettlementPending
+}
+
+// TradeSettlement is trade.Settlement at the current Steam time, using the
+// session time offset.
+func (session *Session) TradeSettlement(trade *Trade) TradeSettlement {
+	return trade.Settlement(time.Now().Add(session.timeOffset))
+}
+
+// IsReverted tells whether the items went back to their owners.
+func (trade *Trade) IsReverted() bool {
+	switch trade.Status {
+	case TradeStatusPartialSupportRollback, TradeStatusFullSupportRollback, TradeStatusSupportRollbackSelective,
+		TradeStatusEscrowRollback, TradeStatusTradeProtectionRollback:
+		return true
+	}
+
+	return false
+}
+
+type TradeHistoryResponse struct {
+	Total        uint32          `json:"total_trades"`
+	More         bool            `json:"more"`
+	Trades       []*Trade        `json:"trades"`
+	Descriptions []*EconItemDesc `json:"descriptions"`
+}
+
+func descriptionKey(classID, instanceID uint64) string {
+	return fmt.Sprintf("%d_%d", classID, instanceID)
+}
+
+// indexDescriptions maps descriptions by "<CLASS_ID>_<INSTANCE_ID>" for
+// attaching them to items.
+func indexDescriptions(descriptions []*EconItemDesc) map[string]*EconItemDesc {
+	index := make(map[string]*EconItemDesc, len(descriptions))
+	for _, desc := range descriptions {
+		index[descriptionKey(desc.ClassID, desc.InstanceID)] = desc
+	}
+
+	return index
+}
+
+func attachTradeDescriptions(trades []*Trade, descriptions []*EconItemDesc) {
+	if len(descriptions) == 0 {
+		return
+	}
+
+	index := indexDescriptions(descriptions)
+	for _, trade := range trades {
+		for _, asset := range trade.AssetsReceived {
+			asset.Desc = index[descriptionKey(asset.ClassID, asset.InstanceID)]
+		}
+
+		for _, asset := range trade.AssetsGiven {
+			asset.Desc = index[descriptionKey(asset.ClassID, asset.InstanceID)]
+		}
+	}
+}
+
+// GetTradeOffersSummary returns offer counters, "new" and "updated" ones are
+// relative to @timeLastVisit.
+func (session *Session) GetTradeOffersSummary(timeLastVisit time.Time) (*TradeOffersSummary, error) {
+	resp, err := session.client.Get(apiGetTradeOffersSummary + url.Values{
+		"key":             {session.apiKey},
+		"time_last_visit": {strconv.FormatInt(timeLastVisit.Unix(), 10)},
+	}.Encode())
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	type Response struct {
+		Inner *TradeOffersSummary `json:"response"`
+	}
+
+	var response Response
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Inner, nil
+}
+
+// GetTradeHistory returns up to @maxTrades completed trades, newest first.
+// To get the next page pass the time and ID of the last trade returned as
+// @startAfterTime and @startAfterTradeID, a zero time starts from the newest.
+func (session *Session) GetTradeHistory(maxTrades uint32, startAfterTime time.Time, startAfterTradeID uint64, getDescriptions, includeFailed bool) (*TradeHistoryResponse, error) {
+	params := url.Values{
+		"key":            {session.apiKey},
+		"max_trades":     {strconv.FormatUint(uint64(maxTrades), 10)},
+		"include_failed": {strconv.FormatBool(includeFailed)},
+		"include_total":  {"true"},
+	}
+
+	if !startAfterTime.IsZero() {
+		params.Set("start_after_time", strconv.FormatInt(startAfterTime.Unix(), 10))
+		params.Set("start_after_tradeid", strconv.FormatUint(startAfterTradeID, 10))
+	}
+
+	if getDescriptions {
+		params.Set("get_descriptions", "true")
+		params.Set("language", session.language)
+	}
+
+	resp, err := session.client.Get(apiGetTradeHistory + params.Encode())
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	type Response struct {
+		Inner *TradeHistoryResponse `json:"response"`
+	}
+
+	var response Response
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	if response.Inner != nil {
+		attachTradeDescriptions(response.Inner.Trades, response.Inner.Descriptions)
+	}
+
+	return response.Inner, nil
+}
+
+// GetTradeStatus returns the trade @tradeID (TradeOffer.ReceiptID), with the
+// new asset and context IDs of each item and their descriptions, use it to
+// locate received items in the inventory once an offer has been accepted.
+func (session *Session) GetTradeStatus(tradeID uint64) (*Trade, error) {
+	resp, err := session.client.Get(apiGetTradeStatus + url.Values{
+		"key":              {session.apiKey},
+		"tradeid":          {strconv.FormatUint(tradeID, 10)},
+		"get_descriptions": {"true"},
+		"language":         {session.language},
+	}.Encode())
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	type Response struct {
+		Inner *TradeHistoryResponse `json:"response"`
+	}
+
+	var response Response
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	if response.Inner == nil || len(response.Inner.Trades) == 0 {
+		return nil, ErrTradeNotFound
+	}
+
+	attachTradeDescriptions(response.Inner.Trades, response.Inner.Descriptions)
+	return response.Inner.Trades[0], nil
+}
@@ -13,8 +13,10 @@ import (
 	"time"
 )
 
+type TradeOfferState uint8
+
 const (
-	TradeStateNone = iota
+	TradeStateNone TradeOfferState = iota
 	TradeStateInvalid
 	TradeStateActive
 	TradeStateAccepted
@@ -26,15 +28,116 @@ const (
 	TradeStateCreatedNeedsConfirmation
 	TradeStateCanceledByTwoFactor
 	TradeStateInEscrow
+	TradeStateReverted // accepted, then reversed during trade protection
 )
 
+type TradeConfirmationMethod uint8
+
 const (
-	TradeConfirmationNone = iota
+	TradeConfirmationNone TradeConfirmationMethod = iota
 	TradeConfirmationEmail
 	TradeConfirmationMobileApp
 	TradeConfirmationMobile
 )
 
+var tradeOfferStateNames = enumNames{
+	uint64(TradeStateNone):                     "None",
+	uint64(TradeStateInvalid):                  "Invalid",
+	uint64(TradeStateActive):                   "Active",
+	uint64(TradeStateAccepted):                 "Accepted",
+	uint64(TradeStateCountered):                "Countered",
+	uint64(TradeStateExpired):                  "Expired",
+	uint64(TradeStateCanceled):                 "Canceled",
+	uint64(TradeStateDeclined):                 "Declined",
+	uint64(TradeStateInvalidItems):             "InvalidItems",
+	uint64(TradeStateCreatedNeedsConfirmation): "CreatedNeedsConfirmation",
+	uint64(TradeStateCanceledByTwoFactor):      "CanceledByTwoFactor",
+	uint64(TradeStateInEscrow):                 "InEscrow",
+	uint64(TradeStateReverted):                 "Reverted",
+}
+
+var tradeConfirmationMethodNames = enumNames{
+	uint64(TradeConfirmationNone):      "None",
+	uint64(TradeConfirmationEmail):     "Email",
+	uint64(TradeConfirmationMobileApp): "MobileApp",
+	uint64(TradeConfirmationMobile):    "Mobile",
+}
+
+func (state TradeOfferState) String() string {
+	return tradeOfferStateNames.format(uint64(state))
+}
+
+func (state TradeOfferState) MarshalText() ([]byte, error) {
+	return []byte(state.String()), nil
+}
+
+func (state *TradeOfferState) UnmarshalText(text []byte) error {
+	v, err := tradeOfferStateNames.parse(text, 8)
+	if err != nil {
+		return err
+	}
+
+	*state = TradeOfferState(v)
+	return nil
+}
+
+// UnmarshalJSON accepts both the numbers sent by Steam and names.
+func (state *TradeOfferState) UnmarshalJSON(data []byte) error {
+	v, ok, err := tradeOfferStateNames.unmarshalJSON(data, 8)
+	if ok {
+		*state = TradeOfferState(v)
+	}
+
+	return err
+}
+
+// IsFinal tells whether the offer can no longer change state.  Accepted
+// offers are final too, though the trade may still be reverted while it is
+// under trade protection, see Trade.Settlement.
+func (state TradeOfferState) IsFinal() bool {
+	switch state {
+	case TradeStateAccepted, TradeStateCountered, TradeStateExpired, TradeStateCanceled,
+		TradeStateDeclined, TradeStateInvalidItems, TradeStateCanceledByTwoFactor, TradeStateReverted:
+		return true
+	}
+
+	return false
+}
+
+// IsPending tells whether the offer still waits on someone, either the
+// partner or a confirmation.
+func (state TradeOfferState) IsPending() bool {
+	return state == TradeStateActive || state == TradeStateCreatedNeedsConfirmation
+}
+
+func (method TradeConfirmationMethod) String() string {
+	return tradeConfirmationMethodNames.format(uint64(method))
+}
+
+func (method TradeConfirmationMethod) MarshalText() ([]byte, error) {
+	return []byte(method.String()), nil
+}
+
+func (method *TradeConfirmationMethod) UnmarshalText(text []byte) error {
+	v, err := tradeConfirmationMethodNames.parse(text, 8)
+	if err != nil {
+		return err
+	}
+
+	*method = TradeConfirmationMethod(v)
+	return nil
+}
+
+// UnmarshalJSON accepts both the numbers sent by Steam and names.
+func (method *TradeConfirmationMethod) UnmarshalJSON(data []byte) error {
+	v, ok, err := tradeConfirmationMethodNames.unmarshalJSON(data, 8)
+	if ok {
+		*method = TradeConfirmationMethod(v)
+	}
+
+	return err
+}
+
 const (
 	TradeFilterNone             = iota
 	TradeFilterSentOffers       = 1 << 0
@@ -57,10 +160,19 @@ var (
 	apiGetTradeOffers    = "https://api.steampowered.com/IEconService/GetTradeOffers/v1/?"
 	apiDeclineTradeOffer = "https://api.steampowered.com/IEconService/DeclineTradeOffer/v1/"
 	apiCancelTradeOffer  = "https://api.steampowered.com/IEconService/CancelTradeOffer/v1/"
-
-	ErrReceiptMatch        = errors.New("unable to match items in trade receipt")
-	ErrCannotAcceptActive  = errors.New("unable to accept a non-active trade")
-	ErrCannotFindOfferInfo = errors.New("unable to match data from trade offer url")
+	apiGetTradeHold      = "https://api.steampowered.com/IEconService/GetTradeHoldDurations/v1/?"
+
+	ErrReceiptMatch         = errors.New("unable to match items in trade receipt")
+	ErrCannotAcceptActive   = errors.New("unable to accept a non-active trade")
+	ErrCannotFindOfferInfo  = errors.New("unable to match data from trade offer url")
+	ErrCannotCounterOwn     = errors.New("unable to counter our own trade offer")
+	ErrConfirmationNotFound = errors.New("unable to find a confirmation for the trade offer")
+	ErrTradeOfferNotFound   = errors.New("trade offer not found")
+	ErrCannotFindEscrowInfo = errors.New("unable to find escrow days on the trade offer page")
+
+	ErrTradeHoldInvalidToken     = errors.New("trade offer token is invalid")
+	ErrTradeHoldPrivateInventory = errors.New("partner inventory is private")
+	ErrTradeHoldTradeBan         = errors.New("partner is unable to trade")
 )
 
 type EconItem struct {
@@ -71,6 +183,8 @@ type EconItem struct {
 	ContextID  uint64 `json:"contextid,string"`
 	Amount     uint16 `json:"amount,string"`
 	Missing    bool   `json:"missing,omitempty"`
+
+	Desc *EconItemDesc `json:"-"` /* May be nil  */
 }
 
 type EconDesc struct {
@@ -110,20 +224,21 @@ type EconItemDesc struct {
 }
 
 type TradeOffer struct {
-	ID                 uint64      `json:"tradeofferid,string"`
-	Partner            uint32      `json:"accountid_other"`
-	ReceiptID          uint64      `json:"tradeid,string"`
-	RecvItems          []*EconItem `json:"items_to_receive"`
-	SendItems          []*EconItem `json:"items_to_give"`
-	Message            string      `json:"message"`
-	State              uint8       `json:"trade_offer_state"`
-	ConfirmationMethod uint8       `json:"confirmation_method"`
-	Created            int64       `json:"time_created"`
-	Updated            int64       `json:"time_updated"`
-	Expires            int64       `json:"expiration_time"`
-	EscrowEndDate      int64       `json:"escrow_end_date"`
-	RealTime           bool        `json:"from_real_time_trade"`
-	IsOurOffer         bool        `json:"is_our_offer"`
+	ID                 uint64                  `json:"tradeofferid,string"`
+	Partner            uint32                  `json:"accountid_other"`
+	ReceiptID          uint64                  `json:"tradeid,string"`
+	RecvItems          []*EconItem             `json:"items_to_receive"`
+	SendItems          []*EconItem             `json:"items_to_give"`
+	Message            string                  `json:"message"`
+	State              TradeOfferState         `json:"trade_offer_state"`
+	ConfirmationMethod TradeConfirmationMethod `json:"confirmation_method"`
+	Created            int64                   `json:"time_created"`
+	Updated            int64                   `json:"time_updated"`
+	Expires            int64                   `json:"expiration_time"`
+	EscrowEndDate      int64                   `json:"escrow_end_date"`
+	RealTime           bool                    `json:"from_real_time_trade"`
+	IsOurOffer         bool                    `json:"is_our_offer"`
+	DelaySettlement    bool                    `json:"delay_settlement"` // the trade goes through trade protection
 }
 
 type TradeOfferResponse struct {
@@ -131,6 +246,14 @@ type TradeOfferResponse struct {
 	SentOffers     []*TradeOffer   `json:"trade_offers_sent"`     // GetTradeOffers
 	ReceivedOffers []*TradeOffer   `json:"trade_offers_received"` // GetTradeOffers
 	Descriptions   []*EconItemDesc `json:"descriptions"`          // GetTradeOffers
+	NextCursor     uint32          `json:"next_cursor"`           // GetTradeOffers, 0 on the last page
+}
+
+type AcceptResult struct {
+	TradeID                    uint64 `json:"tradeid,string"` // same as TradeOffer.ReceiptID
+	MobileConfirmationRequired bool   `json:"needs_mobile_confirmation"`
+	EmailConfirmationRequired  bool   `json:"needs_email_confirmation"`
+	EmailDomain                string `json:"email_domain"`
 }
 
 type APIResponse struct {
@@ -139,8 +262,10 @@ type APIResponse struct {
 
 func (session *Session) GetTradeOffer(id uint64) (*TradeOffer, error) {
 	resp, err := session.client.Get(apiGetTradeOffer + url.Values{
-		"key":          {session.apiKey},
-		"tradeofferid": {strconv.FormatUint(id, 10)},
+		"key":              {session.apiKey},
+		"tradeofferid":     {strconv.FormatUint(id, 10)},
+		"get_descriptions": {"1"},
+		"language":         {session.language},
 	}.Encode())
 	if resp != nil {
 		defer resp.Body.Close()
@@ -155,16 +280,43 @@ func (session *Session) GetTradeOffer(id uint64) (*TradeOffer, error) {
 		return nil, err
 	}
 
+	if response.Inner == nil || response.Inner.Offer == nil {
+		return nil, ErrTradeOfferNotFound
+	}
+
+	attachOfferDescriptions([]*TradeOffer{response.Inner.Offer}, response.Inner.Descriptions)
 	return response.Inner.Offer, nil
 }
 
+// attachOfferDescriptions fills in EconItem.Desc, the same way fetchInventory
+// does for InventoryItem.
+func attachOfferDescriptions(offers []*TradeOffer, descriptions []*EconItemDesc) {
+	if len(descriptions) == 0 {
+		return
+	}
+
+	index := indexDescriptions(descriptions)
+	for _, offer := range offers {
+		for _, item := range offer.SendItems {
+			item.Desc = index[descriptionKey(item.ClassID, item.InstanceID)]
+		}
+
+		for _, item := range offer.RecvItems {
+			item.Desc = index[descriptionKey(item.ClassID, item.InstanceID)]
+		}
+	}
+}
+
 func testBit(bits uint32, bit uint32) bool {
 	return (bits & bit) == bit
 }
 
-func (session *Session) GetTradeOffers(filter uint32, timeCutOff time.Time) (*TradeOfferResponse, error) {
+// GetTradeOffersPage fetches a single page of offers starting at @cursor,
+// pass the returned NextCursor to get the next one (0 for the first page).
+func (session *Session) GetTradeOffersPage(filter uint32, timeCutOff time.Time, cursor uint32) (*TradeOfferResponse, error) {
 	params := url.Values{
-		"key": {session.apiKey},
+		"key":    {session.apiKey},
+		"cursor": {strconv.FormatUint(uint64(cursor), 10)},
 	}
 	if testBit(filter, TradeFilterSentOffers) {
 		params.Set("get_sent_offers", "1")
@@ -175,11 +327,16 @@ func (session *Session) GetTradeOffers(filter uint32, timeCutOff time.Time) (*Tr
 	}
 
 	if testBit(filter, TradeFilterActiveOnly) {
+		// Steam also returns offers which changed state since the cutoff.
 		params.Set("active_only", "1")
+		if !timeCutOff.IsZero() {
+			params.Set("time_historical_cutoff", strconv.FormatInt(timeCutOff.Unix(), 10))
+		}
 	}
 
 	if testBit(filter, TradeFilterItemDescriptions) {
 		params.Set("get_descriptions", "1")
+		params.Set("language", session.language)
 	}
 
 	if testBit(filter, TradeFilterHistoricalOnly) {
@@ -201,9 +358,96 @@ func (session *Session) GetTradeOffers(filter uint32, timeCutOff time.Time) (*Tr
 		return nil, err
 	}
 
+	if response.Inner != nil {
+		attachOfferDescriptions(response.Inner.SentOffers, response.Inner.Descriptions)
+		attachOfferDescriptions(response.Inner.ReceivedOffers, response.Inner.Descriptions)
+	}
+
 	return response.Inner, nil
 }
 
+// TradeOfferIterator walks all pages of GetTradeOffers, e.g.:
+//
+//	it := session.IterateTradeOffers(steam.TradeFilterRecvOffers, time.Now())
+//	for it.Next() {
+//		page := it.Page()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type TradeOfferIterator struct {
+	session    *Session
+	filter     uint32
+	timeCutOff time.Time
+	cursor     uint32
+	page       *TradeOfferResponse
+	err        error
+	done       bool
+}
+
+func (session *Session) IterateTradeOffers(filter uint32, timeCutOff time.Time) *TradeOfferIterator {
+	return &TradeOfferIterator{
+		session:    session,
+		filter:     filter,
+		timeCutOff: timeCutOff,
+	}
+}
+
+// Next fetches the next page, it returns false once all pages have been
+// walked or an error occurred.
+func (it *TradeOfferIterator) Next() bool {
+	if it.done {
+		return false
+	}
+
+	it.page, it.err = it.session.GetTradeOffersPage(it.filter, it.timeCutOff, it.cursor)
+	if it.err != nil || it.page == nil {
+		it.done = true
+		return false
+	}
+
+	it.cursor = it.page.NextCursor
+	it.done = it.cursor == 0
+	return true
+}
+
+func (it *TradeOfferIterator) Page() *TradeOfferResponse {
+	return it.page
+}
+
+func (it *TradeOfferIterator) Err() error {
+	return it.err
+}
+
+// GetTradeOffers fetches all pages and merges them, descriptions shared by
+// several pages are only included once.
+func (session *Session) GetTradeOffers(filter uint32, timeCutOff time.Time) (*TradeOfferResponse, error) {
+	merged := &TradeOfferResponse{}
+	seen := make(map[string]bool)
+
+	it := session.IterateTradeOffers(filter, timeCutOff)
+	for it.Next() {
+		page := it.Page()
+		merged.SentOffers = append(merged.SentOffers, page.SentOffers...)
+		merged.ReceivedOffers = append(merged.ReceivedOffers, page.ReceivedOffers...)
+
+		for _, desc := range page.Descriptions {
+			key := descriptionKey(desc.ClassID, desc.InstanceID)
+			if !seen[key] {
+				seen[key] = true
+				merged.Descriptions = append(merged.Descriptions, desc)
+			}
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
 func (session *Session) GetMyTradeToken() (string, error) {
 	resp, err := session.client.Get("https://steamcommunity.com/my/tradeoffers/privacy")
 	if resp != nil {
@@ -238,39 +482,64 @@ type EscrowSteamGuardInfo struct {
 }
 
 func (session *Session) GetEscrowGuardInfo(sid SteamID, token string) (*EscrowSteamGuardInfo, error) {
-	resp, err := session.client.Get("https://steamcommunity.com/tradeoffer/new/?" + url.Values{
+	info, _, err := session.fetchEscrowGuardInfo("https://steamcommunity.com/tradeoffer/new/?" + url.Values{
 		"partner": {strconv.FormatUint(uint64(sid.GetAccountID()), 10)},
 		"token":   {token},
 	}.Encode())
+	return info, err
+}
+
+// GetTradeOfferEscrowInfo reads the hold days from the page of the received
+// offer @id, unlike GetEscrowGuardInfo it needs no token.
+func (session *Session) GetTradeOfferEscrowInfo(id uint64) (*EscrowSteamGuardInfo, error) {
+	info, found, err := session.fetchEscrowGuardInfo("https://steamcommunity.com/tradeoffer/" + strconv.FormatUint(id, 10) + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	if !found && len(info.ErrorMsg) == 0 {
+		return nil, ErrCannotFindEscrowInfo
+	}
+
+	return info, nil
+}
+
+// fetchEscrowGuardInfo scrapes the hold days from a trade offer page, found
+// is false unless both were on the page.
+func (session *Session) fetchEscrowGuardInfo(pageURL string) (*EscrowSteamGuardInfo, bool, error) {
+	resp, err := session.client.Get(pageURL)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http error: %d", resp.StatusCode)
+		return nil, false, fmt.Errorf("http error: %d", resp.StatusCode)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	var my int64
 	var them int64
 	var errMsg string
+	found := 0
 
 	m := myEscrowExp.FindStringSubmatch(string(body))
 	if m != nil && len(m) == 2 {
 		my, _ = strconv.ParseInt(m[1], 10, 32)
+		found++
 	}
 
 	m = themEscrowExp.FindStringSubmatch(string(body))
 	if m != nil && len(m) == 2 {
 		them, _ = strconv.ParseInt(m[1], 10, 32)
+		found++
 	}
 
 	m = errorMsgExp.FindStringSubmatch(string(body))
@@ -282,10 +551,149 @@ func (session *Session) GetEscrowGuardInfo(sid SteamID, token string) (*EscrowSt
 		MyDays:   my,
 		ThemDays: them,
 		ErrorMsg: errMsg,
-	}, nil
+	}, found == 2, nil
+}
+
+type TradeHoldDurations struct {
+	My    time.Duration // hold on the items we receive
+	Their time.Duration // hold on the items they receive
+	Both  time.Duration // hold applied to the whole trade
+}
+
+func tradeHoldError(msg string) error {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "private"):
+		return ErrTradeHoldPrivateInventory
+	case strings.Contains(lower, "ban") || strings.Contains(lower, "not available to trade"):
+		return ErrTradeHoldTradeBan
+	case strings.Contains(lower, "trade url") || strings.Contains(lower, "token"):
+		return ErrTradeHoldInvalidToken
+	}
+
+	return errors.New(msg)
+}
+
+// GetTradeHoldDurations tells how long items would be held if a trade with
+// @sid happened now, @token is only needed when @sid is not a friend.
+// Without an API key it falls back to scraping GetEscrowGuardInfo.
+func (session *Session) GetTradeHoldDurations(sid SteamID, token string) (*TradeHoldDurations, error) {
+	if len(session.apiKey) == 0 {
+		info, err := session.GetEscrowGuardInfo(sid, token)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(info.ErrorMsg) != 0 {
+			return nil, tradeHoldError(info.ErrorMsg)
+		}
+
+		durations := &TradeHoldDurations{
+			My:    time.Duration(info.MyDays) * 24 * time.Hour,
+			Their: time.Duration(info.ThemDays) * 24 * time.Hour,
+		}
+		durations.Both = durations.My
+		if durations.Their > durations.Both {
+			durations.Both = durations.Their
+		}
+
+		return durations, nil
+	}
+
+	params := url.Values{
+		"key":            {session.apiKey},
+		"steamid_target": {sid.ToString()},
+	}
+	if len(token) != 0 {
+		params.Set("trade_offer_access_token", token)
+	}
+
+	resp, err := session.client.Get(apiGetTradeHold + params.Encode())
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch result := resp.Header.Get("x-eresult"); result {
+	case "", "1":
+	case "15":
+		return nil, ErrTradeHoldInvalidToken
+	case "17":
+		return nil, ErrTradeHoldTradeBan
+	default:
+		return nil, fmt.Errorf("cannot get trade hold durations: %s", result)
+	}
+
+	type Escrow struct {
+		Seconds int64 `json:"escrow_end_duration_seconds"`
+	}
+
+	type Durations struct {
+		My    *Escrow `json:"my_escrow"`
+		Their *Escrow `json:"their_escrow"`
+		Both  *Escrow `json:"both_escrow"`
+	}
+
+	type Response struct {
+		Inner *Durations `json:"response"`
+	}
+
+	var response Response
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	if response.Inner == nil || response.Inner.Both == nil {
+		return nil, ErrTradeHoldInvalidToken
+	}
+
+	durations := &TradeHoldDurations{
+		Both: time.Duration(response.Inner.Both.Seconds) * time.Second,
+	}
+	if response.Inner.My != nil {
+		durations.My = time.Duration(response.Inner.My.Seconds) * time.Second
+	}
+	if response.Inner.Their != nil {
+		durations.Their = time.Duration(response.Inner.Their.Seconds) * time.Second
+	}
+
+	return durations, nil
 }
 
 func (session *Session) SendTradeOffer(offer *TradeOffer, sid SteamID, token string) error {
+	return session.sendTradeOffer(offer, sid, token, 0)
+}
+
+// CounterTradeOffer replaces the received offer @original with a new one to
+// the same partner, @sendItems are the items we give, @recvItems the ones we
+// want.  The original offer is marked as countered and the new one returned.
+func (session *Session) CounterTradeOffer(original *TradeOffer, sendItems, recvItems []*EconItem, message string) (*TradeOffer, error) {
+	if original.IsOurOffer {
+		return nil, ErrCannotCounterOwn
+	}
+
+	var sid SteamID
+	sid.ParseDefaults(original.Partner)
+
+	offer := &TradeOffer{
+		Partner:   original.Partner,
+		SendItems: sendItems,
+		RecvItems: recvItems,
+		Message:   message,
+	}
+	if err := session.sendTradeOffer(offer, sid, "", original.ID); err != nil {
+		return nil, err
+	}
+
+	original.State = TradeStateCountered
+	original.Updated = offer.Created
+	return offer, nil
+}
+
+func (session *Session) sendTradeOffer(offer *TradeOffer, sid SteamID, token string, counteredID uint64) error {
 	content := map[string]interface{}{
 		"newversion": true,
 		"version":    3,
@@ -306,25 +714,36 @@ func (session *Session) SendTradeOffer(offer *TradeOffer, sid SteamID, token str
 		return err
 	}
 
+	values := url.Values{
+		"sessionid":                 {session.sessionID},
+		"serverid":                  {"1"},
+		"partner":                   {sid.ToString()},
+		"tradeoffermessage":         {offer.Message},
+		"json_tradeoffer":           {string(contentJSON)},
+		"trade_offer_create_params": {"{\"trade_offer_access_token\":\"" + token + "\"}"},
+	}
+
+	referer := "https://steamcommunity.com/tradeoffer/new/?" + url.Values{
+		"partner": {strconv.FormatUint(uint64(sid.GetAccountID()), 10)},
+		"token":   {token},
+	}.Encode()
+
+	if counteredID != 0 {
+		// Counter offers are made from the received offer page, no token needed.
+		values.Set("tradeofferid_countered", strconv.FormatUint(counteredID, 10))
+		values.Set("trade_offer_create_params", "{}")
+		referer = "https://steamcommunity.com/tradeoffer/" + strconv.FormatUint(counteredID, 10) + "/"
+	}
+
 	req, err := http.NewRequest(
 		http.MethodPost,
 		"https://steamcommunity.com/tradeoffer/new/send",
-		strings.NewReader(url.Values{
-			"sessionid":                 {session.sessionID},
-			"serverid":                  {"1"},
-			"partner":                   {sid.ToString()},
-			"tradeoffermessage":         {offer.Message},
-			"json_tradeoffer":           {string(contentJSON)},
-			"trade_offer_create_params": {"{\"trade_offer_access_token\":\"" + token + "\"}"},
-		}.Encode()),
+		strings.NewReader(values.Encode()),
 	)
 	if err != nil {
 		return err
 	}
-	req.Header.Add("Referer", "https://steamcommunity.com/tradeoffer/new/?"+url.Values{
-		"partner": {strconv.FormatUint(uint64(sid.GetAccountID()), 10)},
-		"token":   {token},
-	}.Encode())
+	req.Header.Add("Referer", referer)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := session.client.Do(req)
@@ -376,6 +795,9 @@ func (session *Session) SendTradeOffer(offer *TradeOffer, sid SteamID, token str
 	return nil
 }
 
+// GetTradeReceivedItems scrapes the receipt page of a trade.
+//
+// Deprecated: use GetTradeStatus, which reports both the old and new asset IDs.
 func (session *Session) GetTradeReceivedItems(receiptID uint64) ([]*InventoryItem, error) {
 	resp, err := session.client.Get(fmt.Sprintf("https://steamcommunity.com/trade/%d/receipt", receiptID))
 	if resp != nil {
@@ -455,7 +877,9 @@ func (session *Session) CancelTradeOffer(id uint64) error {
 	return nil
 }
 
-func (session *Session) AcceptTradeOffer(id uint64) error {
+// AcceptTradeOffer accepts the offer @id, the result tells whether the trade
+// still has to be confirmed, see AcceptTradeOfferAndConfirm.
+func (session *Session) AcceptTradeOffer(id uint64) (*AcceptResult, error) {
 	tid := strconv.FormatUint(id, 10)
 	postURL := "https://steamcommunity.com/tradeoffer/" + tid
 
@@ -469,7 +893,7 @@ func (session *Session) AcceptTradeOffer(id uint64) error {
 		}.Encode()),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req.Header.Add("Referer", postURL)
@@ -481,37 +905,77 @@ func (session *Session) AcceptTradeOffer(id uint64) error {
 	}
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("http error: %d", resp.StatusCode)
+		return nil, fmt.Errorf("http error: %d", resp.StatusCode)
 	}
 
 	type Response struct {
 		ErrorMessage string `json:"strError"`
+		AcceptResult
 	}
 
 	var response Response
 	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(response.ErrorMessage) != 0 {
-		return errors.New(response.ErrorMessage)
+		return nil, errors.New(response.ErrorMessage)
 	}
 
-	return nil
+	return &response.AcceptResult, nil
+}
+
+// ConfirmTradeOffer answers the mobile confirmation created for the offer @id,
+// which has to be listed already: call it only once Steam said confirmation is
+// needed.  Codes are generated using the session time offset.
+func (session *Session) ConfirmTradeOffer(id uint64, identitySecret string) error {
+	confirmations, err := session.GetConfirmations(identitySecret, time.Now().Add(session.timeOffset).Unix())
+	if err != nil {
+		return err
+	}
+
+	for _, confirmation := range confirmations {
+		if confirmation.Type == ConfirmationTypeTrade && confirmation.CreatorID == id {
+			return session.AnswerConfirmation(confirmation, identitySecret, "allow", time.Now().Add(session.timeOffset).Unix())
+		}
+	}
+
+	return ErrConfirmationNotFound
+}
+
+// AcceptTradeOfferAndConfirm accepts the offer @id and, if Steam asks for it,
+// confirms the trade with @identitySecret right away.
+func (session *Session) AcceptTradeOfferAndConfirm(id uint64, identitySecret string) (*AcceptResult, error) {
+	result, err := session.AcceptTradeOffer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.MobileConfirmationRequired {
+		if err = session.ConfirmTradeOffer(id, identitySecret); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
 }
 
 func (offer *TradeOffer) Send(session *Session, sid SteamID, token string) error {
 	return session.SendTradeOffer(offer, sid, token)
 }
 
-func (offer *TradeOffer) Accept(session *Session) error {
+func (offer *TradeOffer) Accept(session *Session) (*AcceptResult, error) {
 	return session.AcceptTradeOffer(offer.ID)
 }
 
+func (offer *TradeOffer) Counter(session *Session, sendItems, recvItems []*EconItem, message string) (*TradeOffer, error) {
+	return session.CounterTradeOffer(offer, sendItems, recvItems, message)
+}
+
 func (offer *TradeOffer) Cancel(session *Session) error {
 	if offer.IsOurOffer {
 		return session.CancelTradeOffer(offer.ID)
@@ -0,0 +1,233 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrItemDuplicate      = errors.New("item added more than once")
+	ErrItemInvalidContext = errors.New("item has no app or context ID")
+	ErrItemAmount         = errors.New("amount exceeds item stack size")
+	ErrItemNotTradable    = errors.New("item is not tradable")
+	ErrItemMissing        = errors.New("item is no longer in the partner inventory")
+	ErrEmptyTradeOffer    = errors.New("trade offer has no items")
+)
+
+// assetKey identifies an item, asset IDs are only unique within an app and
+// context.
+type assetKey struct {
+	appID     uint32
+	contextID uint64
+	assetID   uint64
+}
+
+// TradeOfferItemError tells which item a TradeOfferBuilder rejected.
+type TradeOfferItemError struct {
+	Item *InventoryItem
+	Err  error
+}
+
+func (e *TradeOfferItemError) Error() string {
+	return fmt.Sprintf("asset %d (app %d, context %d): %v", e.Item.AssetID, e.Item.AppID, e.Item.ContextID, e.Err)
+}
+
+func (e *TradeOfferItemError) Unwrap() error {
+	return e.Err
+}
+
+// TradeOfferBuilder assembles a TradeOffer from inventory items, checking
+// every item as it is added.  Calls can be chained, the first error is kept
+// and returned by Build or Send, e.g.:
+//
+//	offer, err := steam.NewTradeOfferBuilder(session, partner, token).
+//		Give(&mine[0], 1).
+//		Receive(&theirs[3], 0).
+//		VerifyPartnerItems().
+//		Send()
+type TradeOfferBuilder struct {
+	session       *Session
+	partner       SteamID
+	token         string
+	offer         *TradeOffer
+	verifyPartner bool
+	assets        map[assetKey]bool
+	err           error
+}
+
+func NewTradeOfferBuilder(session *Session, partner SteamID, token string) *TradeOfferBuilder {
+	return &TradeOfferBuilder{
+		session: session,
+		partner: partner,
+		token:   token,
+		offer: &TradeOffer{
+			Partner: partner.GetAccountID(),
+		},
+		assets: make(map[assetKey]bool),
+	}
+}
+
+func NewTradeOfferBuilderURL(session *Session, tradeURL *TradeURL) *TradeOfferBuilder {
+	return NewTradeOfferBuilder(session, tradeURL.Partner, tradeURL.Token)
+}
+
+func (builder *TradeOfferBuilder) add(items *[]*EconItem, item *InventoryItem, amount uint64) *TradeOfferBuilder {
+	if builder.err != nil {
+		return builder
+	}
+
+	fail := func(err error) *TradeOfferBuilder {
+		builder.err = &TradeOfferItemError{Item: item, Err: err}
+		return builder
+	}
+
+	if item.AppID == 0 || item.ContextID == 0 {
+		return fail(ErrItemInvalidContext)
+	}
+
+	if item.Desc != nil && item.Desc.Tradable == 0 {
+		return fail(ErrItemNotTradable)
+	}
+
+	if amount == 0 {
+		amount = item.Amount
+	}
+
+	if amount > item.Amount || amount > math.MaxUint16 {
+		return fail(ErrItemAmount)
+	}
+
+	key := assetKey{item.AppID, item.ContextID, item.AssetID}
+	if builder.assets[key] {
+		return fail(ErrItemDuplicate)
+	}
+
+	econItem := &EconItem{
+		AssetID:    item.AssetID,
+		InstanceID: item.InstanceID,
+		ClassID:    item.ClassID,
+		AppID:      item.AppID,
+		ContextID:  item.ContextID,
+		Amount:     uint16(amount),
+	}
+	builder.assets[key] = true
+	*items = append(*items, econItem)
+	return builder
+}
+
+// Give adds one of our items, an @amount of 0 gives the whole stack.
+func (builder *TradeOfferBuilder) Give(item *InventoryItem, amount uint64) *TradeOfferBuilder {
+	return builder.add(&builder.offer.SendItems, item, amount)
+}
+
+// Receive adds one of the partner's items, an @amount of 0 asks for the
+// whole stack.
+func (builder *TradeOfferBuilder) Receive(item *InventoryItem, amount uint64) *TradeOfferBuilder {
+	return builder.add(&builder.offer.RecvItems, item, amount)
+}
+
+func (builder *TradeOfferBuilder) Message(message string) *TradeOfferBuilder {
+	builder.offer.Message = message
+	return builder
+}
+
+// VerifyPartnerItems makes Build load the partner's inventories and check
+// the items we ask for are still there in the requested amount.
+func (builder *TradeOfferBuilder) VerifyPartnerItems() *TradeOfferBuilder {
+	builder.verifyPartner = true
+	return builder
+}
+
+// missingInventoryItems returns the @items which are not, or no longer in
+// the requested amount, in the inventories of @sid.
+func (session *Session) missingInventoryItems(sid SteamID, items []*EconItem) ([]*EconItem, error) {
+	type contextKey struct {
+		appID     uint32
+		contextID uint64
+	}
+
+	wanted := make(map[contextKey][]*EconItem)
+	for _, item := range items {
+		key := contextKey{item.AppID, item.ContextID}
+		wanted[key] = append(wanted[key], item)
+	}
+
+	missing := []*EconItem{}
+	for key, items := range wanted {
+		inventory, err := session.GetFilterableInventory(sid, uint64(key.appID), key.contextID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		amounts := make(map[uint64]uint64, len(inventory))
+		for _, item := range inventory {
+			amounts[item.AssetID] = item.Amount
+		}
+
+		for _, item := range items {
+			if amount, ok := amounts[item.AssetID]; !ok || amount < uint64(item.Amount) {
+				missing = append(missing, item)
+			}
+		}
+	}
+
+	return missing, nil
+}
+
+func (builder *TradeOfferBuilder) checkPartnerItems() error {
+	missing, err := builder.session.missingInventoryItems(builder.partner, builder.offer.RecvItems)
+	if err != nil {
+		return err
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	item := missing[0]
+	return &TradeOfferItemError{
+		Item: &InventoryItem{
+			AppID:      item.AppID,
+			ContextID:  item.ContextID,
+			AssetID:    item.AssetID,
+			ClassID:    item.ClassID,
+			InstanceID: item.InstanceID,
+			Amount:     uint64(item.Amount),
+		},
+		Err: ErrItemMissing,
+	}
+}
+
+// Build runs the remaining checks and returns the offer ready to be sent.
+func (builder *TradeOfferBuilder) Build() (*TradeOffer, error) {
+	if builder.err != nil {
+		return nil, builder.err
+	}
+
+	if len(builder.offer.SendItems) == 0 && len(builder.offer.RecvItems) == 0 {
+		return nil, ErrEmptyTradeOffer
+	}
+
+	if builder.verifyPartner && len(builder.offer.RecvItems) != 0 {
+		if err := builder.checkPartnerItems(); err != nil {
+			return nil, err
+		}
+	}
+
+	return builder.offer, nil
+}
+
+// Send builds the offer and sends it with SendTradeOffer.
+func (builder *TradeOfferBuilder) Send() (*TradeOffer, error) {
+	offer, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = builder.session.SendTradeOffer(offer, builder.partner, builder.token); err != nil {
+		return nil, err
+	}
+
+	return offer, nil
+}
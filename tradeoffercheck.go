@@ -0,0 +1,130 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrOfferNotActive    = errors.New("trade offer is no longer active")
+	ErrOfferItemsMissing = errors.New("trade offer has missing items")
+	ErrOfferItemsChanged = errors.New("trade offer items differ from the ones evaluated")
+	ErrOfferItemsGone    = errors.New("items to give are no longer in our inventory")
+)
+
+// TradeOfferCheckError tells why CheckTradeOffer refused an offer, Items
+// holds the offending items if the reason is about items.
+type TradeOfferCheckError struct {
+	OfferID uint64
+	State   TradeOfferState
+	Items   []*EconItem
+	Err     error
+}
+
+func (e *TradeOfferCheckError) Error() string {
+	if len(e.Items) == 0 {
+		return fmt.Sprintf("trade offer %d (%s): %v", e.OfferID, e.State, e.Err)
+	}
+
+	return fmt.Sprintf("trade offer %d (%s): %v: %d item(s), first asset %d (app %d, context %d)",
+		e.OfferID, e.State, e.Err, len(e.Items), e.Items[0].AssetID, e.Items[0].AppID, e.Items[0].ContextID)
+}
+
+func (e *TradeOfferCheckError) Unwrap() error {
+	return e.Err
+}
+
+// changedEconItems returns the items of @actual which are not in @expected
+// with the same amount, and the items of @expected which are gone.
+func changedEconItems(expected, actual []*EconItem) []*EconItem {
+	amounts := make(map[assetKey]uint16, len(expected))
+	for _, item := range expected {
+		amounts[assetKey{item.AppID, item.ContextID, item.AssetID}] = item.Amount
+	}
+
+	changed := []*EconItem{}
+	for _, item := range actual {
+		key := assetKey{item.AppID, item.ContextID, item.AssetID}
+		if amount, ok := amounts[key]; !ok || amount != item.Amount {
+			changed = append(changed, item)
+		}
+		delete(amounts, key)
+	}
+
+	for _, item := range expected {
+		if _, ok := amounts[assetKey{item.AppID, item.ContextID, item.AssetID}]; ok {
+			changed = append(changed, item)
+		}
+	}
+
+	return changed
+}
+
+// CheckTradeOffer fetches @offer again right before accepting it and makes
+// sure it is still active, none of its items are missing, its items are the
+// ones in @offer and the items we give are still in our inventory.  The
+// fresh offer is returned, a *TradeOfferCheckError tells why it should not
+// be accepted.
+func (session *Session) CheckTradeOffer(offer *TradeOffer) (*TradeOffer, error) {
+	current, err := session.GetTradeOffer(offer.ID)
+	if err == ErrTradeOfferNotFound || (err == nil && current == nil) {
+		return nil, &TradeOfferCheckError{
+			OfferID: offer.ID,
+			State:   offer.State,
+			Err:     ErrOfferNotActive,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	fail := func(err error, items []*EconItem) (*TradeOffer, error) {
+		return current, &TradeOfferCheckError{
+			OfferID: current.ID,
+			State:   current.State,
+			Items:   items,
+			Err:     err,
+		}
+	}
+
+	if current.State != TradeStateActive {
+		return fail(ErrOfferNotActive, nil)
+	}
+
+	missing := []*EconItem{}
+	for _, items := range [][]*EconItem{current.SendItems, current.RecvItems} {
+		for _, item := range items {
+			if item.Missing {
+				missing = append(missing, item)
+			}
+		}
+	}
+
+	if len(missing) != 0 {
+		return fail(ErrOfferItemsMissing, missing)
+	}
+
+	changed := append(changedEconItems(offer.SendItems, current.SendItems), changedEconItems(offer.RecvItems, current.RecvItems)...)
+	if len(changed) != 0 {
+		return fail(ErrOfferItemsChanged, changed)
+	}
+
+	gone, err := session.missingInventoryItems(session.GetSteamID(), current.SendItems)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(gone) != 0 {
+		return fail(ErrOfferItemsGone, gone)
+	}
+
+	return current, nil
+}
+
+// AcceptTradeOfferChecked accepts @offer only if CheckTradeOffer passes.
+func (session *Session) AcceptTradeOfferChecked(offer *TradeOffer) (*AcceptResult, error) {
+	if _, err := session.CheckTradeOffer(offer); err != nil {
+		return nil, err
+	}
+
+	return session.AcceptTradeOffer(offer.ID)
+}
@@ -0,0 +1,301 @@
+package steam
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type TradeOfferEventType uint8
+
+const (
+	TradeOfferEventNew             TradeOfferEventType = iota // received offer we have not seen before
+	TradeOfferEventSentChanged                                // one of our offers changed state
+	TradeOfferEventReceivedChanged                            // a received offer changed state
+	TradeOfferEventItemsInEscrow                              // an offer went into escrow
+	TradeOfferEventError                                      // polling failed, Err is set
+	TradeOfferEventReverted                                   // an accepted offer's trade was reverted
+)
+
+type TradeOfferEvent struct {
+	Type     TradeOfferEventType
+	Offer    *TradeOffer
+	OldState TradeOfferState // TradeStateNone when the offer was not tracked
+	Err      error
+
+	// Evaluation is set on TradeOfferEventNew events by Run when the
+	// manager has an Evaluator, the offer has already been acted on.
+	Evaluation *OfferEvaluation
+}
+
+// TradeOfferPollData is everything TradeOfferManager needs to carry on where
+// it stopped, it is plain data so it can be saved as JSON between restarts.
+type TradeOfferPollData struct {
+	Cutoff int64                      `json:"cutoff"` // unix time passed as time_historical_cutoff
+	States map[uint64]TradeOfferState `json:"states"` // last known state per offer ID
+}
+
+// TradeOfferManager polls trade offers and turns state differences between
+// polls into events.  New received offers are handed to Evaluator if set.
+type TradeOfferManager struct {
+	Interval  time.Duration
+	Evaluator *OfferEvaluator
+
+	session  *Session
+	mutex    sync.Mutex
+	pollData TradeOfferPollData
+	store    Store
+}
+
+// cutoffSlack is taken off the cutoff so offers updated while a poll was in
+// flight are returned again by the next one, the state snapshot makes
+// repeated offers harmless.
+const cutoffSlack = 5 * time.Minute
+
+// defaultPollInterval is used when Interval is not positive.
+const defaultPollInterval = 30 * time.Second
+
+func NewTradeOfferManager(session *Session, interval time.Duration) *TradeOfferManager {
+	return &TradeOfferManager{
+		Interval: interval,
+		session:  session,
+		pollData: TradeOfferPollData{
+			States: make(map[uint64]TradeOfferState),
+		},
+	}
+}
+
+// PollData returns a copy of the current poll cursor and state snapshot.
+func (manager *TradeOfferManager) PollData() *TradeOfferPollData {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	data := &TradeOfferPollData{
+		Cutoff: manager.pollData.Cutoff,
+		States: make(map[uint64]TradeOfferState, len(manager.pollData.States)),
+	}
+	for id, state := range manager.pollData.States {
+		data.States[id] = state
+	}
+
+	return data
+}
+
+// SetPollData restores poll data saved from PollData, call it before polling.
+func (manager *TradeOfferManager) SetPollData(data *TradeOfferPollData) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	manager.pollData.Cutoff = data.Cutoff
+	manager.pollData.States = make(map[uint64]TradeOfferState, len(data.States))
+	for id, state := range data.States {
+		manager.pollData.States[id] = state
+	}
+}
+
+// SetStore loads the poll data saved in @store, if any, and keeps saving it
+// there together with every offer that changed after each poll.
+func (manager *TradeOfferManager) SetStore(store Store) error {
+	data, err := store.LoadPollData()
+	if err != nil && err != ErrNotStored {
+		return err
+	}
+
+	if data != nil {
+		manager.SetPollData(data)
+	}
+
+	manager.mutex.Lock()
+	manager.store = store
+	manager.mutex.Unlock()
+	return nil
+}
+
+// tradeOfferPoll is the outcome of a poll, applied with commit once its
+// events have been handled.
+type tradeOfferPoll struct {
+	events   []*TradeOfferEvent
+	changed  []*TradeOffer
+	pollData TradeOfferPollData
+}
+
+// Poll fetches offers changed since the previous poll once and returns the
+// resulting events.  On the very first poll only active offers are fetched,
+// received ones are reported as new.  The poll data is advanced (and saved
+// to the store) before returning, Run only does so once the events have been
+// delivered so none are lost to a crash.
+func (manager *TradeOfferManager) Poll() ([]*TradeOfferEvent, error) {
+	result, err := manager.poll()
+	if err != nil {
+		return nil, err
+	}
+
+	return result.events, manager.commit(result)
+}
+
+func (manager *TradeOfferManager) poll() (*tradeOfferPoll, error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	now := time.Now().Add(manager.session.timeOffset)
+	cutoff := now
+	if manager.pollData.Cutoff != 0 {
+		cutoff = time.Unix(manager.pollData.Cutoff, 0)
+	}
+
+	resp, err := manager.session.GetTradeOffers(
+		TradeFilterSentOffers|TradeFilterRecvOffers|TradeFilterActiveOnly,
+		cutoff,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[uint64]TradeOfferState, len(manager.pollData.States))
+	for id, state := range manager.pollData.States {
+		states[id] = state
+	}
+
+	returned := make(map[uint64]bool)
+	events := []*TradeOfferEvent{}
+	changed := []*TradeOffer{}
+
+	diff := func(offer *TradeOffer, changedType TradeOfferEventType) {
+		returned[offer.ID] = true
+
+		old, known := states[offer.ID]
+		states[offer.ID] = offer.State
+
+		if !known || old != offer.State {
+			changed = append(changed, offer)
+		}
+
+		if !known {
+			if changedType == TradeOfferEventReceivedChanged && offer.State == TradeStateActive {
+				events = append(events, &TradeOfferEvent{Type: TradeOfferEventNew, Offer: offer})
+			} else if offer.State == TradeStateReverted {
+				// Accepted offers are pruned once final, a revert brings
+				// them back with an unknown previous state.
+				events = append(events, &TradeOfferEvent{Type: TradeOfferEventReverted, Offer: offer})
+			}
+			return
+		}
+
+		if old == offer.State {
+			return
+		}
+
+		events = append(events, &TradeOfferEvent{Type: changedType, Offer: offer, OldState: old})
+		if offer.State == TradeStateInEscrow {
+			events = append(events, &TradeOfferEvent{Type: TradeOfferEventItemsInEscrow, Offer: offer, OldState: old})
+		} else if offer.State == TradeStateReverted {
+			events = append(events, &TradeOfferEvent{Type: TradeOfferEventReverted, Offer: offer, OldState: old})
+		}
+	}
+
+	for _, offer := range resp.SentOffers {
+		diff(offer, TradeOfferEventSentChanged)
+	}
+
+	for _, offer := range resp.ReceivedOffers {
+		diff(offer, TradeOfferEventReceivedChanged)
+	}
+
+	// Offers in a final state which fell behind the cutoff will not be
+	// returned anymore, no need to keep them around.
+	for id, state := range states {
+		if !returned[id] && state.IsFinal() {
+			delete(states, id)
+		}
+	}
+
+	return &tradeOfferPoll{
+		events:  events,
+		changed: changed,
+		pollData: TradeOfferPollData{
+			Cutoff: now.Add(-cutoffSlack).Unix(),
+			States: states,
+		},
+	}, nil
+}
+
+// commit advances the poll data to @result and saves it with the changed
+// offers.
+func (manager *TradeOfferManager) commit(result *tradeOfferPoll) error {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	manager.pollData = result.pollData
+	if manager.store == nil {
+		return nil
+	}
+
+	for _, offer := range result.changed {
+		if err := manager.store.SaveTradeOffer(offer); err != nil {
+			return err
+		}
+	}
+
+	return manager.store.SavePollData(&manager.pollData)
+}
+
+// Run polls every Interval and sends the events to the returned channel,
+// which is closed once @ctx is done.  New offers are evaluated before their
+// event is sent.  The poll data only advances once every event of a poll has
+// been received, events interrupted by @ctx are reported again by the next
+// Run.
+func (manager *TradeOfferManager) Run(ctx context.Context) <-chan *TradeOfferEvent {
+	events := make(chan *TradeOfferEvent)
+
+	go func() {
+		defer close(events)
+
+		interval := manager.Interval
+		if interval <= 0 {
+			interval = defaultPollInterval
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			var polled []*TradeOfferEvent
+			result, err := manager.poll()
+			if err != nil {
+				polled = []*TradeOfferEvent{{Type: TradeOfferEventError, Err: err}}
+			} else {
+				polled = result.events
+			}
+
+			for _, event := range polled {
+				if event.Type == TradeOfferEventNew && manager.Evaluator != nil {
+					event.Evaluation = manager.Evaluator.Handle(ctx, event.Offer)
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if result != nil {
+				if err = manager.commit(result); err != nil {
+					select {
+					case events <- &TradeOfferEvent{Type: TradeOfferEventError, Err: err}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return events
+}
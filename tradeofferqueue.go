@@ -0,0 +1,196 @@
+package steam
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TradeOfferJob is an offer waiting in a TradeOfferQueue, the fields after
+// Token are filled in by the queue before the job's callback is called.
+type TradeOfferJob struct {
+	Offer   *TradeOffer
+	Partner SteamID
+	Token   string
+
+	Attempts   int   // number of times sending was tried
+	Sent       bool  // the offer exists on Steam, never resend it
+	Err        error // why sending failed
+	Confirmed  bool  // the offer needed and got a mobile confirmation
+	ConfirmErr error // why confirming a sent offer failed or was interrupted
+
+	done func(job *TradeOfferJob)
+}
+
+// TradeOfferQueue sends trade offers one at a time, at most one every
+// Interval.  Sends failing with Steam's "try again later" are retried up to
+// MaxRetries times, RetryDelay apart, and created offers are confirmed right
+// away when the queue has an identity secret.
+type TradeOfferQueue struct {
+	Interval   time.Duration
+	MaxRetries int
+	RetryDelay time.Duration
+
+	session        *Session
+	identitySecret string
+
+	mutex    sync.Mutex
+	jobs     []*TradeOfferJob
+	wake     chan struct{}
+	lastSend time.Time
+}
+
+func NewTradeOfferQueue(session *Session, identitySecret string, interval time.Duration) *TradeOfferQueue {
+	return &TradeOfferQueue{
+		Interval:       interval,
+		MaxRetries:     3,
+		RetryDelay:     30 * time.Second,
+		session:        session,
+		identitySecret: identitySecret,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// Push queues @offer for @partner, @done (may be nil) is called from the
+// queue goroutine once it has been sent or given up on.
+func (queue *TradeOfferQueue) Push(offer *TradeOffer, partner SteamID, token string, done func(job *TradeOfferJob)) *TradeOfferJob {
+	job := &TradeOfferJob{
+		Offer:   offer,
+		Partner: partner,
+		Token:   token,
+		done:    done,
+	}
+
+	queue.mutex.Lock()
+	queue.jobs = append(queue.jobs, job)
+	queue.mutex.Unlock()
+
+	select {
+	case queue.wake <- struct{}{}:
+	default:
+	}
+
+	return job
+}
+
+func (queue *TradeOfferQueue) PushURL(offer *TradeOffer, tradeURL *TradeURL, done func(job *TradeOfferJob)) *TradeOfferJob {
+	return queue.Push(offer, tradeURL.Partner, tradeURL.Token, done)
+}
+
+// Len returns the number of jobs waiting to be sent.
+func (queue *TradeOfferQueue) Len() int {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	return len(queue.jobs)
+}
+
+func (queue *TradeOfferQueue) pop() *TradeOfferJob {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	if len(queue.jobs) == 0 {
+		return nil
+	}
+
+	job := queue.jobs[0]
+	queue.jobs[0] = nil
+	queue.jobs = queue.jobs[1:]
+	return job
+}
+
+func isTryAgainLater(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "try again later")
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// confirmAttempts is how many times the queue looks for the confirmation of
+// a created offer, it may take a moment to show up.
+const confirmAttempts = 3
+
+func (queue *TradeOfferQueue) process(ctx context.Context, job *TradeOfferJob) {
+	for {
+		if err := sleepContext(ctx, queue.Interval-time.Since(queue.lastSend)); err != nil {
+			job.Err = err
+			return
+		}
+
+		job.Attempts++
+		job.Err = queue.session.SendTradeOffer(job.Offer, job.Partner, job.Token)
+		queue.lastSend = time.Now()
+
+		if job.Err == nil || !isTryAgainLater(job.Err) || job.Attempts > queue.MaxRetries {
+			break
+		}
+
+		if err := sleepContext(ctx, queue.RetryDelay); err != nil {
+			job.Err = err
+			return
+		}
+	}
+
+	if job.Err != nil {
+		return
+	}
+
+	job.Sent = true
+	if job.Offer.State != TradeStateCreatedNeedsConfirmation || len(queue.identitySecret) == 0 {
+		return
+	}
+
+	for i := 0; i < confirmAttempts; i++ {
+		job.ConfirmErr = queue.session.ConfirmTradeOffer(job.Offer.ID, queue.identitySecret)
+		if job.ConfirmErr != ErrConfirmationNotFound || i == confirmAttempts-1 {
+			break
+		}
+
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			job.ConfirmErr = err
+			return
+		}
+	}
+
+	if job.ConfirmErr == nil {
+		job.Confirmed = true
+		job.Offer.State = TradeStateActive
+	}
+}
+
+// Run sends queued offers until @ctx is done, jobs still queued then stay
+// queued for the next Run.  A job interrupted by @ctx is completed with the
+// context error in Err, or in ConfirmErr if the offer was already sent.
+func (queue *TradeOfferQueue) Run(ctx context.Context) error {
+	for {
+		job := queue.pop()
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-queue.wake:
+				continue
+			}
+		}
+
+		queue.process(ctx, job)
+
+		if job.done != nil {
+			job.done(job)
+		}
+	}
+}
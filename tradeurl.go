@@ -0,0 +1,143 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const tradeURLPath = "/tradeoffer/new/"
+
+var (
+	tradeTokenExp = regexp.MustCompile("^[a-zA-Z0-9_-]{8}$")
+
+	ErrInvalidTradeURL   = errors.New("invalid trade offer URL")
+	ErrInvalidTradeToken = errors.New("invalid trade offer token")
+)
+
+// TradeURL is a trade offer link in the following form:
+//
+//	https://steamcommunity.com/tradeoffer/new/?partner=<ACCOUNT_ID>&token=<TOKEN>
+//
+// Token may be empty for friends, who can be sent offers without it.
+type TradeURL struct {
+	Partner SteamID
+	Token   string
+}
+
+func ParseTradeURL(rawURL string) (*TradeURL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, ErrInvalidTradeURL
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, ErrInvalidTradeURL
+	}
+
+	if u.Host != "steamcommunity.com" && u.Host != "www.steamcommunity.com" {
+		return nil, ErrInvalidTradeURL
+	}
+
+	if strings.TrimSuffix(u.Path, "/")+"/" != tradeURLPath {
+		return nil, ErrInvalidTradeURL
+	}
+
+	query := u.Query()
+	accountID, err := strconv.ParseUint(query.Get("partner"), 10, 32)
+	if err != nil || accountID == 0 {
+		return nil, ErrInvalidTradeURL
+	}
+
+	token := query.Get("token")
+	if len(token) != 0 && !tradeTokenExp.MatchString(token) {
+		return nil, ErrInvalidTradeToken
+	}
+
+	tradeURL := &TradeURL{Token: token}
+	tradeURL.Partner.ParseDefaults(uint32(accountID))
+	return tradeURL, nil
+}
+
+func (tradeURL *TradeURL) String() string {
+	params := url.Values{
+		"partner": {strconv.FormatUint(uint64(tradeURL.Partner.GetAccountID()), 10)},
+	}
+	if len(tradeURL.Token) != 0 {
+		params.Set("token", tradeURL.Token)
+	}
+
+	return "https://steamcommunity.com" + tradeURLPath + "?" + params.Encode()
+}
+
+func (session *Session) SendTradeOfferURL(offer *TradeOffer, tradeURL *TradeURL) error {
+	return session.SendTradeOffer(offer, tradeURL.Partner, tradeURL.Token)
+}
+
+func (session *Session) GetEscrowGuardInfoURL(tradeURL *TradeURL) (*EscrowSteamGuardInfo, error) {
+	return session.GetEscrowGuardInfo(tradeURL.Partner, tradeURL.Token)
+}
+
+func (session *Session) GetMyTradeURL() (*TradeURL, error) {
+	token, err := session.GetMyTradeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TradeURL{
+		Partner: session.oauth.SteamID,
+		Token:   token,
+	}, nil
+}
+
+// RegenerateTradeToken does what "Create new URL" does on the privacy page,
+// the previous token (and so every trade URL handed out) stops working.
+func (session *Session) RegenerateTradeToken() (string, error) {
+	req, err := http.NewRequest(
+		http.MethodPost,
+		"https://steamcommunity.com/profiles/"+session.oauth.SteamID.ToString()+"/tradeoffers/newtradeurl",
+		strings.NewReader(url.Values{
+			"sessionid": {session.sessionID},
+		}.Encode()),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Add("Referer", "https://steamcommunity.com/profiles/"+session.oauth.SteamID.ToString()+"/tradeoffers/privacy")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := session.client.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("http error: %d", resp.StatusCode)
+	}
+
+	// The new token is returned as a bare JSON string.
+	var token string
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	if !tradeTokenExp.MatchString(token) {
+		return "", ErrInvalidTradeToken
+	}
+
+	return token, nil
+}
+
+func (offer *TradeOffer) SendURL(session *Session, tradeURL *TradeURL) error {
+	return session.SendTradeOfferURL(offer, tradeURL)
+}
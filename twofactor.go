@@ -17,21 +17,42 @@ type TwoFactorInfo struct {
 	RevocationCode string `json:"revocation_code"`
 	URI            string `json:"uri"`
 	ServerTime     uint64 `json:"server_time,string"`
+	AccountName    string `json:"account_name"`
 	TokenGID       string `json:"token_gid"`
 }
 
+type DisableTwoFactorInfo struct {
+	Success               bool
+	ServerTime            uint64
+	AttemptsRemaining     uint32
+	RevocationCodeInvalid bool
+}
+
 type FinalizeTwoFactorInfo struct {
 	Status     uint32 `json:"status"`
 	ServerTime uint64 `json:"server_time,string"`
 }
 
+const (
+	SteamGuardSchemeEmail = 1 // fall back to email codes
+	SteamGuardSchemeNone  = 2 // no Steam Guard at all
+)
+
 const (
 	enableTwoFactorURL   = "https://api.steampowered.com/ITwoFactorService/AddAuthenticator/v1/"
 	finalizeTwoFactorURL = "https://api.steampowered.com/ITwoFactorService/FinalizeAddAuthenticator/v1/"
 	disableTwoFactorURL  = "https://api.steampowered.com/ITwoFactorService/RemoveAuthenticator/v1/"
+
+	removeViaChallengeStartURL    = "https://api.steampowered.com/ITwoFactorService/RemoveAuthenticatorViaChallengeStart/v1/"
+	removeViaChallengeContinueURL = "https://api.steampowered.com/ITwoFactorService/RemoveAuthenticatorViaChallengeContinue/v1/"
 )
 
-var ErrCannotDisable = errors.New("unable to process disable two factor request")
+var (
+	ErrCannotDisable         = errors.New("unable to process disable two factor request")
+	ErrInvalidRevocationCode = errors.New("invalid revocation code")
+	ErrCannotStartChallenge  = errors.New("unable to start authenticator removal challenge")
+	ErrInvalidSMSCode        = errors.New("invalid SMS code for authenticator removal challenge")
+)
 
 func (session *Session) EnableTwoFactor() (*TwoFactorInfo, error) {
 	resp, err := session.client.PostForm(enableTwoFactorURL, url.Values{
@@ -90,23 +111,29 @@ func (session *Session) FinalizeTwoFactor(authCode, mobileCode string) (*Finaliz
 	return response.Inner, nil
 }
 
-func (session *Session) DisableTwoFactor(revocationCode string) error {
+// DisableTwoFactor removes the authenticator using @revocationCode and switches
+// the account to @scheme (SteamGuardSchemeEmail or SteamGuardSchemeNone).
+// When Steam rejects the request the info is still returned along with the error,
+// so callers can check how many revocation attempts are left before retrying.
+func (session *Session) DisableTwoFactor(revocationCode string, scheme uint32) (*DisableTwoFactorInfo, error) {
 	resp, err := session.client.PostForm(disableTwoFactorURL, url.Values{
 		"steamid":           {session.oauth.SteamID.ToString()},
 		"access_token":      {session.oauth.Token},
 		"revocation_code":   {revocationCode},
-		"steamguard_scheme": {"1"},
+		"steamguard_scheme": {strconv.FormatUint(uint64(scheme), 10)},
 	})
 	if resp != nil {
 		defer resp.Body.Close()
 	}
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	type Disabled struct {
-		Success bool `json:"success"`
+		Success           bool    `json:"success"`
+		ServerTime        uint64  `json:"server_time,string"`
+		AttemptsRemaining *uint32 `json:"revocation_attempts_remaining"`
 	}
 	type Response struct {
 		Inner *Disabled `json:"response"`
@@ -114,12 +141,104 @@ func (session *Session) DisableTwoFactor(revocationCode string) error {
 
 	var response Response
 	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	if response.Inner == nil {
+		return nil, ErrCannotDisable
+	}
+
+	info := &DisableTwoFactorInfo{
+		Success:    response.Inner.Success,
+		ServerTime: response.Inner.ServerTime,
+	}
+	if response.Inner.AttemptsRemaining != nil {
+		info.AttemptsRemaining = *response.Inner.AttemptsRemaining
+		// Steam only reports the remaining attempts when the code was rejected.
+		info.RevocationCodeInvalid = !info.Success
+	}
+
+	if !info.Success {
+		if info.RevocationCodeInvalid {
+			return info, ErrInvalidRevocationCode
+		}
+
+		return info, ErrCannotDisable
+	}
+
+	return info, nil
+}
+
+// RemoveAuthenticatorViaChallengeStart asks Steam to send an SMS code to the phone
+// attached to the account, this is the first step of moving an authenticator
+// to this device without knowing its revocation code.
+func (session *Session) RemoveAuthenticatorViaChallengeStart() error {
+	resp, err := session.client.PostForm(removeViaChallengeStartURL, url.Values{
+		"steamid":      {session.oauth.SteamID.ToString()},
+		"access_token": {session.oauth.Token},
+	})
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
 		return err
 	}
 
-	if !response.Inner.Success {
-		return ErrCannotDisable
+	type Started struct {
+		Success bool `json:"success"`
+	}
+	type Response struct {
+		Inner *Started `json:"response"`
+	}
+
+	var response Response
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return err
+	}
+
+	if response.Inner == nil || !response.Inner.Success {
+		return ErrCannotStartChallenge
 	}
 
 	return nil
 }
+
+// RemoveAuthenticatorViaChallengeContinue finishes the transfer started by
+// RemoveAuthenticatorViaChallengeStart using the @smsCode received, the old
+// authenticator stops working and the returned info holds the new secrets.
+// Store them (especially the revocation code) before doing anything else.
+func (session *Session) RemoveAuthenticatorViaChallengeContinue(smsCode string) (*TwoFactorInfo, error) {
+	resp, err := session.client.PostForm(removeViaChallengeContinueURL, url.Values{
+		"steamid":            {session.oauth.SteamID.ToString()},
+		"access_token":       {session.oauth.Token},
+		"sms_code":           {smsCode},
+		"generate_new_token": {"true"},
+	})
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	type Continued struct {
+		Success          bool           `json:"success"`
+		ReplacementToken *TwoFactorInfo `json:"replacement_token"`
+	}
+	type Response struct {
+		Inner *Continued `json:"response"`
+	}
+
+	var response Response
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	if response.Inner == nil || !response.Inner.Success || response.Inner.ReplacementToken == nil {
+		return nil, ErrInvalidSMSCode
+	}
+
+	return response.Inner.ReplacementToken, nil
+}